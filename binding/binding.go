@@ -0,0 +1,265 @@
+// binding/binding.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package binding converts HTTP requests into structures labeled with `map-to`.
+//
+// The source of each member is selected by in label option: query, header, form, body or path.
+// For example, `map-to:"page,in=query"`. Members without in option are looked up in the JSON body.
+package binding
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/streamwest-1629/convertobject"
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+const (
+	// Label option to select the source of the member.
+	OptionIn = `in`
+
+	// Members are looked up in the URL query.
+	InQuery = `query`
+	// Members are looked up in request headers, with canonical header keys.
+	InHeader = `header`
+	// Members are looked up in the form body, application/x-www-form-urlencoded or multipart/form-data.
+	InForm = `form`
+	// Members are looked up in the JSON object body.
+	InBody = `body`
+	// Members are looked up in path parameters, given by PathParam.
+	InPath = `path`
+
+	// Maximum memory used to parse multipart form.
+	MaxMultipartMemory = 32 << 20
+)
+
+var (
+	// Get the path parameter of the request, used for members with in=path.
+	// Set it for the router in use, members with in=path are absent while it is nil.
+	PathParam func(r *http.Request, name string) (string, bool)
+
+	// Maximum size of request bodies read for members with in=body or in=form, larger bodies are invalid requests.
+	MaxBodySize int64 = 10 << 20
+)
+
+func init() {
+	convertobject.LabelOptions[OptionIn] = true
+}
+
+// Convert the request into the destination, which is the pointer of the structure.
+// Returned error is *Error when the request is invalid, which renders a report for 400 Bad Request.
+func Bind(r *http.Request, dst interface{}) error {
+	return BindWith(r, dst, convertobject.Options{})
+}
+
+// Convert the request into the destination with the options, same as Bind otherwise.
+// With convertobject.CollectErrors, returned *Error contains errors of all members.
+func BindWith(r *http.Request, dst interface{}, opts convertobject.Options) error {
+
+	compiled, err := convertobject.CompileStruct(dst)
+	if err != nil {
+		return err
+	}
+
+	src := &source{request: r}
+	mapped, in, err := src.build(compiled, "")
+	if err != nil {
+		return err
+	}
+
+	if err := compiled.ConvertWith(mapped, dst, "", opts); err != nil {
+		return newError(err, in)
+	}
+	return nil
+}
+
+type source struct {
+	request    *http.Request
+	body       map[string]interface{}
+	bodyRead   bool
+	formParsed bool
+	limited    bool
+}
+
+// Build the source map of the structure's members, and where each member is from.
+func (s *source) build(compiled *convertobject.Struct, property string) (map[string]interface{}, map[string]string, error) {
+
+	mapped, in := make(map[string]interface{}), make(map[string]string)
+	for i := range compiled.Members {
+
+		member := &compiled.Members[i]
+		if member.Embed {
+			// embedded pointers are allocated by the converter when their members are found
+			convert := member.Convert
+			for ptr, ok := convert.(*convertobject.Ptr); ok; ptr, ok = convert.(*convertobject.Ptr) {
+				convert = ptr.Internal
+			}
+			if embedded, ok := convert.(*convertobject.Struct); ok {
+				if embeddedMap, embeddedIn, err := s.build(embedded, property); err != nil {
+					return nil, nil, err
+				} else {
+					for key, val := range embeddedMap {
+						mapped[key] = val
+					}
+					for key, val := range embeddedIn {
+						in[key] = val
+					}
+				}
+			}
+			continue
+		}
+
+		where, ok := member.Options[OptionIn]
+		if !ok {
+			where = InBody
+		}
+		in[member.Keyname] = where
+
+		if val, exist, err := s.lookup(member, where); err != nil {
+			return nil, nil, err
+		} else if exist {
+			mapped[member.Keyname] = val
+		}
+	}
+	return mapped, in, nil
+}
+
+func (s *source) lookup(member *convertobject.Member, where string) (interface{}, bool, error) {
+
+	r, multiple := s.request, isMultiple(member.Convert)
+	switch where {
+	case InQuery:
+		return values(r.URL.Query()[member.Keyname], multiple)
+	case InHeader:
+		return values(r.Header.Values(member.Keyname), multiple)
+	case InForm:
+		if err := s.parseForm(); err != nil {
+			return nil, false, err
+		}
+		return values(r.PostForm[member.Keyname], multiple)
+	case InPath:
+		if PathParam == nil {
+			return nil, false, nil
+		}
+		val, exist := PathParam(r, member.Keyname)
+		return val, exist, nil
+	case InBody:
+		if err := s.readBody(); err != nil {
+			return nil, false, err
+		}
+		val, exist := s.body[member.Keyname]
+		return val, exist, nil
+	default:
+		return nil, false, errors.New(member.Keyname + ": not supported in option: " + where)
+	}
+}
+
+func (s *source) parseForm() error {
+
+	if s.formParsed {
+		return nil
+	}
+	s.formParsed = true
+	s.limitBody()
+
+	mediaType, _, _ := mime.ParseMediaType(s.request.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		if err := s.request.ParseMultipartForm(MaxMultipartMemory); err != nil {
+			return &Error{Errors: []FieldError{{In: InForm, Message: err.Error()}}}
+		}
+	} else if err := s.request.ParseForm(); err != nil {
+		return &Error{Errors: []FieldError{{In: InForm, Message: err.Error()}}}
+	}
+	return nil
+}
+
+func (s *source) readBody() error {
+
+	if s.bodyRead {
+		return nil
+	}
+	s.bodyRead = true
+
+	if s.request.Body == nil {
+		return nil
+	} else if mediaType, _, _ := mime.ParseMediaType(s.request.Header.Get("Content-Type")); mediaType != "" && mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+	s.limitBody()
+
+	decoder := json.NewDecoder(s.request.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&s.body); err == io.EOF {
+		return nil
+	} else if err != nil {
+		return &Error{Errors: []FieldError{{In: InBody, Message: "invalid JSON body: " + err.Error()}}}
+	}
+	return nil
+}
+
+// Limit the request body to MaxBodySize, once for the request.
+func (s *source) limitBody() {
+	if !s.limited && s.request.Body != nil {
+		s.request.Body = http.MaxBytesReader(nil, s.request.Body, MaxBodySize)
+		s.limited = true
+	}
+}
+
+// Get the source value from values of the key, as []interface{} when the member has multiple values.
+func values(vals []string, multiple bool) (interface{}, bool, error) {
+
+	if len(vals) == 0 {
+		return nil, false, nil
+	} else if !multiple {
+		return vals[0], true, nil
+	}
+
+	elems := make([]interface{}, len(vals))
+	for i, val := range vals {
+		elems[i] = val
+	}
+	return elems, true, nil
+}
+
+func isMultiple(convert convertobject.Convert) bool {
+	switch c := convert.(type) {
+	case *convertobject.Slice:
+		return true
+	case *convertobject.Ptr:
+		return isMultiple(c.Internal)
+	default:
+		return false
+	}
+}
+
+// Get the top-level keyname of the property, as `items` of `items[0].name`.
+func topKeyname(property string) string {
+	if at := strings.IndexAny(property, ".["); at >= 0 {
+		return property[:at]
+	}
+	return property
+}
+
+func propertyOf(err error) string {
+	if propErr, ok := err.(util.PropertyError); ok {
+		return propErr.Property()
+	}
+	return ""
+}
@@ -0,0 +1,161 @@
+// binding/binding_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binding_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject"
+	"github.com/streamwest-1629/convertobject/binding"
+)
+
+type Paging struct {
+	Page  int `map-to:"page,in=query,min=1"`
+	Limit int `map-to:"limit,in=query,max=100"`
+}
+
+type updateRequest struct {
+	ID      int64    `map-to:"id!,in=path"`
+	Tags    []string `map-to:"tag,in=query"`
+	Token   string   `map-to:"X-Token!,in=header"`
+	Name    string   `map-to:"name!"`
+	Age     int      `map-to:"age"`
+	Comment string   `map-to:"comment,in=form"`
+	Paging  `map-to:"<-"`
+}
+
+func init() {
+	binding.PathParam = func(r *http.Request, name string) (string, bool) {
+		if name == "id" && strings.HasPrefix(r.URL.Path, "/users/") {
+			return strings.TrimPrefix(r.URL.Path, "/users/"), true
+		}
+		return "", false
+	}
+}
+
+func TestBind(t *testing.T) {
+
+	r := httptest.NewRequest(http.MethodPut, "/users/42?tag=a&tag=b&page=2", strings.NewReader(`{"name":"Ada","age":36}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Token", "secret")
+
+	dst := updateRequest{}
+	if err := binding.Bind(r, &dst); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := updateRequest{ID: 42, Tags: []string{"a", "b"}, Token: "secret", Name: "Ada", Age: 36, Paging: Paging{Page: 2}}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("want %+v, has %+v", want, dst)
+	}
+}
+
+func TestBindEmbeddedPointer(t *testing.T) {
+
+	type listRequest struct {
+		Query   string `map-to:"q,in=query"`
+		*Paging `map-to:"<-"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/users?q=ada&page=3&limit=10", nil)
+	dst := listRequest{}
+	if err := binding.Bind(r, &dst); err != nil {
+		t.Fatal(err.Error())
+	}
+	if want := (listRequest{Query: "ada", Paging: &Paging{Page: 3, Limit: 10}}); !reflect.DeepEqual(dst, want) {
+		t.Errorf("want %+v, has %+v", want, dst)
+	}
+}
+
+func TestBindForm(t *testing.T) {
+
+	r := httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader("comment=hello&name=ignored"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Token", "secret")
+
+	dst := updateRequest{}
+	err := binding.BindWith(r, &dst, convertobject.Options{Errors: convertobject.CollectErrors})
+	if dst.Comment != "hello" {
+		t.Errorf("want comment from form, has %q", dst.Comment)
+	}
+	if bindErr, ok := err.(*binding.Error); !ok {
+		t.Errorf("want *binding.Error for missing name, has %v", err)
+	} else if fieldErr := bindErr.Errors[0]; fieldErr.Property != "name" || fieldErr.In != binding.InBody {
+		t.Errorf("want error of name in body, has %+v", fieldErr)
+	}
+}
+
+func TestBindReport(t *testing.T) {
+
+	r := httptest.NewRequest(http.MethodPut, "/users/x?page=0&limit=1000", strings.NewReader(`{"name":"Ada"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	err := binding.BindWith(r, &updateRequest{}, convertobject.Options{Errors: convertobject.CollectErrors})
+	bindErr, ok := err.(*binding.Error)
+	if !ok {
+		t.Fatalf("want *binding.Error, has %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	if err := bindErr.Report().Write(w); err != nil {
+		t.Fatal(err.Error())
+	} else if w.Code != http.StatusBadRequest {
+		t.Errorf("want status 400, has %d", w.Code)
+	}
+
+	report := binding.Report{}
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	in := make(map[string]string)
+	for _, fieldErr := range report.Errors {
+		in[fieldErr.Property] = fieldErr.In
+	}
+	if want := map[string]string{"id": "path", "X-Token": "header", "page": "query", "limit": "query"}; !reflect.DeepEqual(in, want) {
+		t.Errorf("want errors %v, has %+v", want, report.Errors)
+	}
+}
+
+func TestBindBodySize(t *testing.T) {
+
+	limit := binding.MaxBodySize
+	binding.MaxBodySize = 16
+	t.Cleanup(func() { binding.MaxBodySize = limit })
+
+	r := httptest.NewRequest(http.MethodPut, "/users/1", strings.NewReader(`{"name":"`+strings.Repeat("a", 32)+`"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Token", "secret")
+
+	err := binding.Bind(r, &updateRequest{})
+	if bindErr, ok := err.(*binding.Error); !ok {
+		t.Errorf("want *binding.Error, has %v", err)
+	} else if fieldErr := bindErr.Errors[0]; fieldErr.In != binding.InBody || !strings.Contains(fieldErr.Message, "too large") {
+		t.Errorf("want error of too large body, has %+v", fieldErr)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader("comment="+strings.Repeat("a", 32)))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Token", "secret")
+	if bindErr, ok := binding.Bind(r, &updateRequest{}).(*binding.Error); !ok || bindErr.Errors[0].In != binding.InForm {
+		t.Errorf("form: want error of too large body, has %v", bindErr)
+	}
+}
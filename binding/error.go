@@ -0,0 +1,87 @@
+// binding/error.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binding
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+type (
+	// The error of binding the invalid request.
+	// With convertobject.CollectErrors given to BindWith, it contains all errors of members.
+	Error struct {
+		Errors []FieldError
+	}
+
+	// The error of the member.
+	FieldError struct {
+		Property string `json:"property,omitempty"`
+		In       string `json:"in,omitempty"`
+		Message  string `json:"message"`
+	}
+
+	// Structured report of the invalid request, rendered as JSON.
+	Report struct {
+		Status  int          `json:"status"`
+		Message string       `json:"message"`
+		Errors  []FieldError `json:"errors"`
+	}
+)
+
+func newError(err error, in map[string]string) *Error {
+
+	errs := util.Errors{}
+	errs.Append(err)
+
+	result := &Error{Errors: make([]FieldError, len(errs))}
+	for i, err := range errs {
+		property := propertyOf(err)
+		result.Errors[i] = FieldError{
+			Property: property,
+			In:       in[topKeyname(property)],
+			Message:  err.Error(),
+		}
+	}
+	return result
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		messages[i] = fieldErr.Message
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Report of the error, with status 400 Bad Request.
+func (e *Error) Report() *Report {
+	return &Report{
+		Status:  http.StatusBadRequest,
+		Message: http.StatusText(http.StatusBadRequest),
+		Errors:  e.Errors,
+	}
+}
+
+// Write the report as JSON response.
+func (r *Report) Write(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(r.Status)
+	return json.NewEncoder(w).Encode(r)
+}
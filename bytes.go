@@ -0,0 +1,58 @@
+// bytes.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject
+
+import (
+	"reflect"
+
+	"github.com/streamwest-1629/convertobject/standard"
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+var byteType = reflect.TypeOf(byte(0))
+
+func (b *Bytes) Convert(src, dst interface{}, property string) error {
+
+	destination := reflect.ValueOf(dst)
+	if destination.Kind() != reflect.Ptr || destination.Type().Elem() != b.Type {
+		panic(util.ErrInvalidType(property, reflect.New(b.Type).Interface(), dst))
+	}
+	destination = destination.Elem()
+
+	buf := []byte(nil)
+	if err := standard.ConvertoEncodedBytes(src, &buf, property, b.Encoding); err != nil {
+		return err
+	}
+
+	if b.Type.Kind() == reflect.Array {
+		if length := b.Type.Len(); len(buf) != length {
+			return util.ErrInvalidLength(property, length, len(buf))
+		}
+		reflect.Copy(destination, reflect.ValueOf(buf))
+	} else {
+		destination.Set(reflect.ValueOf(buf).Convert(b.Type))
+	}
+	return nil
+}
+
+func isBytes(__type reflect.Type) bool {
+	switch __type.Kind() {
+	case reflect.Slice, reflect.Array:
+		return __type.Elem() == byteType
+	default:
+		return false
+	}
+}
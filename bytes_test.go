@@ -0,0 +1,90 @@
+// bytes_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject"
+)
+
+type bytesTarget struct {
+	Raw    []byte   `map-to:"raw"`
+	Key    []byte   `map-to:"key,encoding=base64"`
+	Digest [4]byte  `map-to:"digest,encoding=hex"`
+	Salt   *[]byte  `map-to:"salt,encoding=hex"`
+	Array  [2]byte  `map-to:"array"`
+	Tokens [][]byte `map-to:"tokens"`
+}
+
+func TestBytes(t *testing.T) {
+
+	dst := bytesTarget{}
+	if err := convertobject.DirectConvert(map[string]interface{}{
+		"raw":    "raw",
+		"key":    "aGVsbG8=",
+		"digest": "DEADbeef",
+		"salt":   "0102",
+		"array":  []byte{1, 2},
+		"tokens": []interface{}{"a", []byte("b")},
+	}, &dst); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if string(dst.Raw) != "raw" || string(dst.Key) != "hello" {
+		t.Errorf("want raw and hello, has %q and %q", dst.Raw, dst.Key)
+	}
+	if dst.Digest != [4]byte{0xde, 0xad, 0xbe, 0xef} {
+		t.Errorf("want deadbeef, has %x", dst.Digest)
+	}
+	if dst.Salt == nil || !bytes.Equal(*dst.Salt, []byte{1, 2}) {
+		t.Errorf("want salt 0102, has %v", dst.Salt)
+	}
+	if dst.Array != [2]byte{1, 2} || len(dst.Tokens) != 2 || string(dst.Tokens[1]) != "b" {
+		t.Errorf("array and tokens: %+v", dst)
+	}
+}
+
+func TestBytesError(t *testing.T) {
+
+	for src, want := range map[string]string{
+		"digest": "digest is invalid length (want: 4, has: 3)",
+		"key":    "key is invalid base64 string (at offset 4)",
+		"salt":   "salt is invalid hex string (at offset 0)",
+	} {
+		val := map[string]string{"digest": "010203", "key": "aGVs*G8=", "salt": "zz"}[src]
+		if err := convertobject.DirectConvert(map[string]interface{}{src: val}, &bytesTarget{}); err == nil {
+			t.Errorf("%s: want error", src)
+		} else if err.Error() != want {
+			t.Errorf("%s: want %q, has %q", src, want, err.Error())
+		}
+	}
+
+	type unsupported struct {
+		Key []byte `map-to:"key,encoding=base32"`
+	}
+	if _, err := convertobject.CompileStructIndepended(unsupported{}); err == nil {
+		t.Error("encoding=base32: want compile error")
+	}
+
+	type notBytes struct {
+		Key string `map-to:"key,encoding=hex"`
+	}
+	if _, err := convertobject.CompileStructIndepended(notBytes{}); err == nil {
+		t.Error("encoding for string: want compile error")
+	}
+}
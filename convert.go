@@ -16,18 +16,44 @@
 package convertobject
 
 import (
+	"encoding/json"
+	"math/big"
 	"reflect"
+	"time"
 
 	"github.com/streamwest-1629/convertobject/standard"
 	"github.com/streamwest-1629/convertobject/util"
 )
 
+var (
+	// Converters selected by the exact type, prior to the kind of the type.
+	TypeConverters = map[reflect.Type]Convert{
+		reflect.TypeOf(big.Int{}):       CoercionFunc(standard.ConvertoBigIntWith),
+		reflect.TypeOf(big.Float{}):     CoercionFunc(standard.ConvertoBigFloatWith),
+		reflect.TypeOf(big.Rat{}):       CoercionFunc(standard.ConvertoBigRatWith),
+		reflect.TypeOf(json.Number("")): CoercionFunc(standard.ConvertoJSONNumberWith),
+		reflect.TypeOf(time.Time{}):     ConvertFunc(standard.ConvertoTime),
+	}
+)
+
+type (
+	// Converters which take options, and give them to converters of members and elements.
+	optionConvert interface {
+		convertOpts(src, dst interface{}, property string, opts *Options) error
+	}
+)
+
 // TODO: WRITE COMMENT
 func DirectConvert(src interface{}, dst interface{}) error {
+	return ConvertWith(src, dst, Options{})
+}
+
+// Convert from src into the destination pointer with the options, DirectConvert converts with the zero Options.
+func ConvertWith(src interface{}, dst interface{}, opts Options) error {
 	if c, err := selectConvert(reflect.TypeOf(dst).Elem(), &PreCompiled); err != nil {
 		return err
 	} else {
-		return c.Convert(src, dst, "")
+		return convertWith(c, src, dst, "", &opts)
 	}
 }
 
@@ -43,36 +69,73 @@ func (c ConvertFunc) Convert(src, dst interface{}, property string) error {
 	return c(src, dst, property)
 }
 
+func (c CoercionFunc) Convert(src, dst interface{}, property string) error {
+	return c(src, dst, property, standard.CoercionDefault)
+}
+
+func (c CoercionFunc) convertOpts(src, dst interface{}, property string, opts *Options) error {
+	return c(src, dst, property, opts.Coercion)
+}
+
+// Convert with the options, converters which don't take options are called by Convert.
+func convertWith(c Convert, src, dst interface{}, property string, opts *Options) error {
+	if optional, ok := c.(optionConvert); ok {
+		return optional.convertOpts(src, dst, property, opts)
+	}
+	return c.Convert(src, dst, property)
+}
+
 func selectConvert(__type reflect.Type, cache *map[string]*Struct) (Convert, error) {
+
+	// registered types
+	if convert, exist := TypeConverters[__type]; exist {
+		return convert, nil
+	}
+
+	// named types with constant names
+	if convert, ok, err := selectEnum(__type); ok {
+		return convert, err
+	}
+
+	// sql.NullString, sql.NullInt64 and other sql.Scanner types
+	if convert, ok, err := selectScanner(__type, cache); ok {
+		return convert, err
+	}
+
+	// []byte and [N]byte
+	if isBytes(__type) {
+		return &Bytes{Type: __type, Encoding: standard.EncodingRaw}, nil
+	}
+
 	switch __type.Kind() {
 	case reflect.Int64:
-		return ConvertFunc(standard.ConvertoInt64), nil
+		return CoercionFunc(standard.ConvertoInt64With), nil
 	case reflect.Int32:
-		return ConvertFunc(standard.ConvertoInt32), nil
+		return CoercionFunc(standard.ConvertoInt32With), nil
 	case reflect.Int16:
-		return ConvertFunc(standard.ConvertoInt16), nil
+		return CoercionFunc(standard.ConvertoInt16With), nil
 	case reflect.Int8:
-		return ConvertFunc(standard.ConvertoInt8), nil
+		return CoercionFunc(standard.ConvertoInt8With), nil
 	case reflect.Int:
-		return ConvertFunc(standard.ConvertoInt), nil
+		return CoercionFunc(standard.ConvertoIntWith), nil
 	case reflect.Uint64:
-		return ConvertFunc(standard.ConvertoUint64), nil
+		return CoercionFunc(standard.ConvertoUint64With), nil
 	case reflect.Uint32:
-		return ConvertFunc(standard.ConvertoUint32), nil
+		return CoercionFunc(standard.ConvertoUint32With), nil
 	case reflect.Uint16:
-		return ConvertFunc(standard.ConvertoUint16), nil
+		return CoercionFunc(standard.ConvertoUint16With), nil
 	case reflect.Uint8:
-		return ConvertFunc(standard.ConvertoUint8), nil
+		return CoercionFunc(standard.ConvertoUint8With), nil
 	case reflect.Uint:
-		return ConvertFunc(standard.ConvertoUint), nil
+		return CoercionFunc(standard.ConvertoUintWith), nil
 	case reflect.Float64:
-		return ConvertFunc(standard.ConvertoFloat64), nil
+		return CoercionFunc(standard.ConvertoFloat64With), nil
 	case reflect.Float32:
-		return ConvertFunc(standard.ConvertoFloat32), nil
+		return CoercionFunc(standard.ConvertoFloat32With), nil
 	case reflect.Bool:
-		return ConvertFunc(standard.ConvertoBool), nil
+		return CoercionFunc(standard.ConvertoBoolWith), nil
 	case reflect.String:
-		return ConvertFunc(standard.ConvertoString), nil
+		return CoercionFunc(standard.ConvertoStringWith), nil
 	case reflect.Ptr:
 		elem := __type.Elem()
 		if gen, err := selectConvert(elem, cache); err != nil {
@@ -94,25 +157,7 @@ func selectConvert(__type reflect.Type, cache *map[string]*Struct) (Convert, err
 			}, nil
 		}
 	case reflect.Struct:
-
-		__name := util.TypeFullname(__type)
-
-		// check cache
-		if val, exist := (*cache)[__name]; exist {
-			return val, nil
-		} else {
-
-			result := new(Struct)
-			(*cache)[__name] = result
-
-			// compile
-			if err := compileStruct(__type, result, cache); err != nil {
-				delete(*cache, __name)
-				return nil, err
-			} else {
-				return (*cache)[__name], nil
-			}
-		}
+		return selectStruct(__type, cache)
 
 	case reflect.Map:
 
@@ -120,13 +165,36 @@ func selectConvert(__type reflect.Type, cache *map[string]*Struct) (Convert, err
 		keyKind, valKind := keyType.Kind(), valType.Kind()
 
 		if keyKind == reflect.Interface && valKind == reflect.Interface {
-			return ConvertFunc(standard.ConvertoInterfaceKeyInterfaceMap), nil
+			return &Map{Type: __type, Internal: ConvertFunc(standard.ConvertoInterfaceKeyInterfaceMap)}, nil
 		} else if keyKind == reflect.String && valKind == reflect.Interface {
-			return ConvertFunc(standard.ConvertoStringKeyInterfaceMap), nil
+			return &Map{Type: __type, Internal: CoercionFunc(standard.ConvertoStringKeyInterfaceMapWith)}, nil
 		} else if keyKind == reflect.String && valKind == reflect.String {
-			return ConvertFunc(standard.ConvertoStringKeyStringMap), nil
+			return &Map{Type: __type, Internal: CoercionFunc(standard.ConvertoStringKeyStringMapWith)}, nil
 		}
 	}
 
 	panic("not supported type: " + util.TypeFullname(__type))
 }
+
+// Select compiled converter of the structure type, which is compiled and cached unless found in the cache.
+func selectStruct(__type reflect.Type, cache *map[string]*Struct) (*Struct, error) {
+
+	__name := util.TypeFullname(__type)
+
+	// check cache
+	if val, exist := (*cache)[__name]; exist {
+		return val, nil
+	} else {
+
+		result := new(Struct)
+		(*cache)[__name] = result
+
+		// compile
+		if err := compileStruct(__type, result, cache); err != nil {
+			delete(*cache, __name)
+			return nil, err
+		} else {
+			return (*cache)[__name], nil
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// enum.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+var (
+	// Registered name tables for named types, guarded by enumsLock.
+	enums     = make(map[reflect.Type]*Enum)
+	enumsLock sync.RWMutex
+)
+
+// Register name table of the named type, used when converting from strings.
+//
+// All values in names must have the same named type, whose underlying kind is integer, float or string.
+// When caseInsensitive is true, source strings are compared with names ignoring case.
+//
+// Structures in PreCompiled which have members of the type are removed, to be compiled again with the name table.
+// Structures compiled before registration and held elsewhere keep converting the type by its underlying kind,
+// so that register enums before compiling structures, such as in init().
+func RegisterEnum(names map[string]interface{}, caseInsensitive bool) (registered *Enum, err error) {
+
+	if registered, err = registerEnum(names, caseInsensitive); err != nil {
+		return nil, err
+	}
+	for name, compiled := range PreCompiled {
+		if compiled.Type != nil && dependsOn(compiled.Type, registered.Type, make(map[reflect.Type]bool)) {
+			delete(PreCompiled, name)
+		}
+	}
+	return registered, nil
+}
+
+func registerEnum(names map[string]interface{}, caseInsensitive bool) (registered *Enum, err error) {
+
+	if len(names) == 0 {
+		return nil, errors.New("cannot register enum without names")
+	}
+
+	registered = &Enum{
+		CaseInsensitive: caseInsensitive,
+		names:           make([]string, 0, len(names)),
+		values:          make([]reflect.Value, 0, len(names)),
+	}
+
+	keys := make([]string, 0, len(names))
+	for name := range names {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+
+	for _, name := range keys {
+		val := reflect.ValueOf(names[name])
+		if !val.IsValid() {
+			return nil, errors.New("enum value of " + name + " is nil")
+		} else if registered.Type == nil {
+			registered.Type = val.Type()
+		} else if registered.Type != val.Type() {
+			return nil, errors.New("enum values have different types: " +
+				util.TypeFullname(registered.Type) + " and " + util.TypeFullname(val.Type()))
+		}
+		registered.names = append(registered.names, name)
+		registered.values = append(registered.values, val)
+	}
+
+	if basic := basicType(registered.Type.Kind()); basic == nil || basic.Kind() == reflect.Bool {
+		return nil, errors.New("enum type must be integer, float or string: " + util.TypeFullname(registered.Type))
+	} else if internal, err := selectConvert(basic, &PreCompiled); err != nil {
+		return nil, err
+	} else {
+		registered.internal = internal
+	}
+
+	enumsLock.Lock()
+	enums[registered.Type] = registered
+	enumsLock.Unlock()
+	return registered, nil
+}
+
+// Get the registered name table of the named type.
+func LookupEnum(__type reflect.Type) (registered *Enum, exist bool) {
+
+	enumsLock.RLock()
+	defer enumsLock.RUnlock()
+	registered, exist = enums[__type]
+	return
+}
+
+// Force to register name table of the named type.
+// If function failed to register it, occer panic().
+func RegisterEnumForce(names map[string]interface{}, caseInsensitive bool) *Enum {
+
+	if registered, err := RegisterEnum(names, caseInsensitive); err != nil {
+		panic(err.Error())
+	} else {
+		return registered
+	}
+}
+
+// Names returns allowed names in sorted order.
+func (e *Enum) Names() []string {
+	return append([]string{}, e.names...)
+}
+
+func (e *Enum) Convert(src, dst interface{}, property string) error {
+
+	destination := reflect.ValueOf(dst)
+	if destination.Kind() != reflect.Ptr || destination.Type().Elem() != e.Type {
+		panic(util.ErrInvalidType(property, reflect.New(e.Type).Interface(), dst))
+	}
+	destination = destination.Elem()
+
+	if name, ok := src.(string); ok {
+		if val, exist := e.lookup(name); exist {
+			destination.Set(val)
+			return nil
+		}
+	} else if val := reflect.ValueOf(src); val.IsValid() && val.Type() == e.Type {
+		if e.contains(val) {
+			destination.Set(val)
+			return nil
+		}
+		return util.ErrInvalidEnum(property, src, e.names)
+	}
+
+	// convert via underlying kind
+	buf := reflect.New(basicType(e.Type.Kind()))
+	if err := e.internal.Convert(src, buf.Interface(), property); err != nil {
+		return util.ErrInvalidEnum(property, src, e.names)
+	} else if val := buf.Elem().Convert(e.Type); !e.contains(val) {
+		return util.ErrInvalidEnum(property, src, e.names)
+	} else {
+		destination.Set(val)
+		return nil
+	}
+}
+
+// Encode returns the name of the value, the reverse of Convert.
+// When some names have the same value, the first name in sorted order is returned.
+func (e *Enum) Encode(src interface{}, property string) (interface{}, error) {
+
+	val := reflect.ValueOf(src)
+	if val.IsValid() && val.Kind() == reflect.Ptr && val.Type().Elem() == e.Type {
+		val = val.Elem()
+	}
+	if !val.IsValid() || val.Type() != e.Type {
+		return nil, util.ErrInvalidType(property, reflect.Zero(e.Type).Interface(), src)
+	}
+
+	for i, registered := range e.values {
+		if registered.Interface() == val.Interface() {
+			return e.names[i], nil
+		}
+	}
+	return nil, util.ErrInvalidEnum(property, src, e.names)
+}
+
+func (e *Enum) lookup(name string) (reflect.Value, bool) {
+	for i, registered := range e.names {
+		if registered == name || (e.CaseInsensitive && strings.EqualFold(registered, name)) {
+			return e.values[i], true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func (e *Enum) contains(val reflect.Value) bool {
+	for _, registered := range e.values {
+		if registered.Interface() == val.Interface() {
+			return true
+		}
+	}
+	return false
+}
+
+func selectEnum(__type reflect.Type) (Convert, bool, error) {
+
+	if registered, exist := LookupEnum(__type); exist {
+		return registered, true, nil
+	} else if kind := __type.Kind(); kind == reflect.Interface || kind == reflect.Ptr {
+		return nil, false, nil
+	}
+
+	// the pointer has methods declared with both value and pointer receivers
+	namer, ok := reflect.New(__type).Interface().(EnumNamer)
+	if !ok {
+		return nil, false, nil
+	}
+	caseInsensitive := false
+	if asserted, ok := namer.(EnumCaseInsensitive); ok {
+		caseInsensitive = asserted.EnumCaseInsensitive()
+	}
+
+	if registered, err := registerEnum(namer.EnumNames(), caseInsensitive); err != nil {
+		return nil, true, err
+	} else if registered.Type != __type {
+		return nil, true, errors.New("EnumNames of " + util.TypeFullname(__type) +
+			" returns values of " + util.TypeFullname(registered.Type))
+	} else {
+		return registered, true, nil
+	}
+}
+
+// Reports whether values of the type contain values of the target type, through members, elements and pointers.
+func dependsOn(__type, target reflect.Type, visited map[reflect.Type]bool) bool {
+
+	if __type == target {
+		return true
+	} else if visited[__type] {
+		return false
+	}
+	visited[__type] = true
+
+	switch __type.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return dependsOn(__type.Elem(), target, visited)
+	case reflect.Map:
+		return dependsOn(__type.Key(), target, visited) || dependsOn(__type.Elem(), target, visited)
+	case reflect.Struct:
+		for i, l := 0, __type.NumField(); i < l; i++ {
+			if dependsOn(__type.Field(i).Type, target, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func basicType(kind reflect.Kind) reflect.Type {
+	switch kind {
+	case reflect.Int64:
+		return reflect.TypeOf(int64(0))
+	case reflect.Int32:
+		return reflect.TypeOf(int32(0))
+	case reflect.Int16:
+		return reflect.TypeOf(int16(0))
+	case reflect.Int8:
+		return reflect.TypeOf(int8(0))
+	case reflect.Int:
+		return reflect.TypeOf(int(0))
+	case reflect.Uint64:
+		return reflect.TypeOf(uint64(0))
+	case reflect.Uint32:
+		return reflect.TypeOf(uint32(0))
+	case reflect.Uint16:
+		return reflect.TypeOf(uint16(0))
+	case reflect.Uint8:
+		return reflect.TypeOf(uint8(0))
+	case reflect.Uint:
+		return reflect.TypeOf(uint(0))
+	case reflect.Float64:
+		return reflect.TypeOf(float64(0))
+	case reflect.Float32:
+		return reflect.TypeOf(float32(0))
+	case reflect.Bool:
+		return reflect.TypeOf(false)
+	case reflect.String:
+		return reflect.TypeOf("")
+	default:
+		return nil
+	}
+}
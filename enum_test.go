@@ -0,0 +1,183 @@
+// enum_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject"
+)
+
+type enumColor int
+
+type enumShape string
+
+func (enumShape) EnumNames() map[string]interface{} {
+	return map[string]interface{}{
+		"circle": enumShape("c"),
+		"square": enumShape("s"),
+	}
+}
+
+type enumSize int
+
+func (enumSize) EnumNames() map[string]interface{} {
+	return map[string]interface{}{
+		"small": enumSize(1),
+		"large": enumSize(2),
+	}
+}
+
+func (enumSize) EnumCaseInsensitive() bool {
+	return true
+}
+
+type enumLate int
+
+type enumLevel uint8
+
+func (*enumLevel) EnumNames() map[string]interface{} {
+	return map[string]interface{}{
+		"debug": enumLevel(0),
+		"info":  enumLevel(1),
+	}
+}
+
+func (*enumLevel) EnumCaseInsensitive() bool {
+	return true
+}
+
+type enumTarget struct {
+	Color enumColor `map-to:"color"`
+	Shape enumShape `map-to:"shape"`
+	Size  enumSize  `map-to:"size"`
+	Level enumLevel `map-to:"level"`
+}
+
+type enumLateTarget struct {
+	Late enumLate `map-to:"late"`
+}
+
+func init() {
+	convertobject.RegisterEnumForce(map[string]interface{}{
+		"red":   enumColor(1),
+		"green": enumColor(2),
+		"blue":  enumColor(3),
+	}, true)
+}
+
+func TestEnum(t *testing.T) {
+
+	for _, c := range []struct {
+		src  map[string]interface{}
+		want enumTarget
+	}{
+		{map[string]interface{}{"color": "green", "shape": "square", "size": "large"}, enumTarget{Color: 2, Shape: "s", Size: 2}},
+		{map[string]interface{}{"color": "RED", "size": "Small"}, enumTarget{Color: 1, Size: 1}},
+		{map[string]interface{}{"color": 3, "shape": "c"}, enumTarget{Color: 3, Shape: "c"}},
+		{map[string]interface{}{"color": "2"}, enumTarget{Color: 2}},
+		{map[string]interface{}{"level": "INFO"}, enumTarget{Level: 1}},
+	} {
+		dst := enumTarget{}
+		if err := convertobject.DirectConvert(c.src, &dst); err != nil {
+			t.Errorf("%v: %s", c.src, err.Error())
+		} else if dst != c.want {
+			t.Errorf("%v: want %+v, has %+v", c.src, c.want, dst)
+		}
+	}
+
+	for _, src := range []map[string]interface{}{
+		{"color": "purple"},
+		{"color": 4},
+		{"shape": "Circle"},
+		{"shape": "x"},
+		{"size": 3},
+		{"level": 2},
+	} {
+		if err := convertobject.DirectConvert(src, &enumTarget{}); err == nil {
+			t.Errorf("%v: want error", src)
+		}
+	}
+}
+
+func TestEnumError(t *testing.T) {
+
+	err := convertobject.DirectConvert(map[string]interface{}{"color": "purple"}, &enumTarget{})
+	if err == nil {
+		t.Fatal("want error")
+	} else if msg := err.Error(); !strings.HasPrefix(msg, "color ") || !strings.Contains(msg, "(allowed: blue, green, red)") {
+		t.Errorf("want error of color listing allowed names, has %q", msg)
+	}
+}
+
+func TestEnumEncode(t *testing.T) {
+
+	registered, exist := convertobject.LookupEnum(reflect.TypeOf(enumColor(0)))
+	if !exist {
+		t.Fatal("enumColor is not registered")
+	} else if names := registered.Names(); strings.Join(names, ",") != "blue,green,red" {
+		t.Errorf("want sorted names, has %v", names)
+	}
+
+	if name, err := registered.Encode(enumColor(2), "color"); err != nil {
+		t.Error(err.Error())
+	} else if name != "green" {
+		t.Errorf("want green, has %v", name)
+	}
+
+	color := enumColor(3)
+	if name, err := registered.Encode(&color, "color"); err != nil || name != "blue" {
+		t.Errorf("pointer: want blue, has %v, %v", name, err)
+	}
+	if _, err := registered.Encode(enumColor(9), "color"); err == nil {
+		t.Error("unregistered value: want error")
+	}
+	if _, err := registered.Encode(9, "color"); err == nil {
+		t.Error("other type: want error")
+	}
+}
+
+func TestRegisterEnum(t *testing.T) {
+
+	if _, err := convertobject.RegisterEnum(map[string]interface{}{}, false); err == nil {
+		t.Error("no names: want error")
+	}
+	if _, err := convertobject.RegisterEnum(map[string]interface{}{"a": enumLate(1), "b": 2}, false); err == nil {
+		t.Error("different types: want error")
+	}
+	if _, err := convertobject.RegisterEnum(map[string]interface{}{"yes": true}, false); err == nil {
+		t.Error("bool: want error")
+	}
+
+	// compiled before registration, removed from PreCompiled by registration
+	before := convertobject.CompileStructForce(enumLateTarget{})
+	convertobject.RegisterEnumForce(map[string]interface{}{"one": enumLate(1)}, false)
+	if convertobject.CompileStructForce(enumLateTarget{}) == before {
+		t.Error("want structure compiled again after registration")
+	}
+
+	dst := enumLateTarget{}
+	if err := convertobject.DirectConvert(map[string]interface{}{"late": "one"}, &dst); err != nil {
+		t.Error(err.Error())
+	} else if dst.Late != 1 {
+		t.Errorf("want 1, has %d", dst.Late)
+	}
+	if err := convertobject.DirectConvert(map[string]interface{}{"late": 5}, &enumLateTarget{}); err == nil {
+		t.Error("want error after registration")
+	}
+}
@@ -0,0 +1,191 @@
+// env.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+const (
+	// Separator of environment variable names, between prefix, keynames and slice indexes.
+	EnvSeparator = `_`
+	// Separator of slice elements written in an environment variable.
+	EnvListSeparator = `,`
+)
+
+// Convert environment variables of the process into the destination, see FromEnv.
+func ConvertEnv(prefix string, dst interface{}) error {
+
+	if compiled, err := CompileStruct(dst); err != nil {
+		return err
+	} else if src, err := compiled.FromEnv(prefix, os.Environ()); err != nil {
+		return err
+	} else {
+		return compiled.Convert(src, dst, "")
+	}
+}
+
+// Build the source map from environment variables, formatted as os.Environ, with the prefix.
+//
+// Variable names are the prefix and keynames in upper case joined with '_', where '-' in keynames is replaced with '_'.
+// For example, APP_SERVER_PORT is the member `server.port` with prefix APP.
+// Members of slices are given with indexes from 0, as APP_HOSTS_0 and APP_HOSTS_1, or separated with ',' as APP_HOSTS=a,b.
+// Indexes must be contiguous, a missing index such as APP_HOSTS_1 between APP_HOSTS_0 and APP_HOSTS_2 is an error.
+// Members of maps are given with their keys in lower case, as APP_LABELS_TEAM.
+// Embedded members share the prefix with the structure.
+func (s *Struct) FromEnv(prefix string, environ []string) (map[string]interface{}, error) {
+
+	vars := make(map[string]string, len(environ))
+	for _, env := range environ {
+		if at := strings.Index(env, "="); at > 0 {
+			vars[env[:at]] = env[at+1:]
+		}
+	}
+
+	if len(prefix) > 0 && !strings.HasSuffix(prefix, EnvSeparator) {
+		prefix += EnvSeparator
+	}
+	return envStruct(s, prefix, "", vars)
+}
+
+func envStruct(s *Struct, prefix, property string, vars map[string]string) (map[string]interface{}, error) {
+
+	result := make(map[string]interface{})
+	for i := range s.Members {
+
+		member := &s.Members[i]
+		if member.Embed {
+			if embedded := structOf(member.Convert); embedded != nil {
+				if mapped, err := envStruct(embedded, prefix, property, vars); err != nil {
+					return nil, err
+				} else {
+					for key, val := range mapped {
+						result[key] = val
+					}
+				}
+			}
+			continue
+		}
+
+		name := prefix + envName(member.Keyname)
+		if val, exist, err := envValue(member.Convert, s.Type.Field(member.MemberAt).Type, name, member.property(property), vars); err != nil {
+			return nil, err
+		} else if exist {
+			result[member.Keyname] = val
+		}
+	}
+	return result, nil
+}
+
+func envValue(convert Convert, __type reflect.Type, name, property string, vars map[string]string) (interface{}, bool, error) {
+
+	for __type.Kind() == reflect.Ptr {
+		__type = __type.Elem()
+	}
+
+	if internal := structOf(convert); internal != nil {
+		if mapped, err := envStruct(internal, name+EnvSeparator, property, vars); err != nil {
+			return nil, false, err
+		} else if len(mapped) > 0 {
+			return mapped, true, nil
+		}
+		return nil, false, nil
+	} else if slice := sliceOf(convert); slice != nil {
+		elems := []interface{}{}
+		for _, index := range envIndexes(name, vars) {
+			elemProperty := property + "[" + strconv.Itoa(index) + "]"
+			if elem, exist, err := envValue(slice.Internal, __type.Elem(), name+EnvSeparator+strconv.Itoa(index), elemProperty, vars); err != nil {
+				return nil, false, err
+			} else if !exist {
+				continue
+			} else if index != len(elems) {
+				return nil, false, util.ErrCannotFound(property + "[" + strconv.Itoa(len(elems)) + "]")
+			} else {
+				elems = append(elems, elem)
+			}
+		}
+		if len(elems) > 0 {
+			return elems, true, nil
+		} else if val, exist := vars[name]; exist && structOf(slice.Internal) == nil {
+			for _, elem := range strings.Split(val, EnvListSeparator) {
+				elems = append(elems, strings.TrimSpace(elem))
+			}
+			return elems, true, nil
+		}
+		return nil, false, nil
+	} else if __type.Kind() == reflect.Map {
+		mapped := make(map[string]interface{})
+		for key, val := range vars {
+			if strings.HasPrefix(key, name+EnvSeparator) {
+				mapped[strings.ToLower(key[len(name)+len(EnvSeparator):])] = val
+			}
+		}
+		if len(mapped) > 0 {
+			return mapped, true, nil
+		}
+		return nil, false, nil
+	}
+
+	val, exist := vars[name]
+	return val, exist, nil
+}
+
+// Indexes following the name in variable names, such as 0 and 2 of APP_HOSTS_0 and APP_HOSTS_2_NAME, in ascending order.
+func envIndexes(name string, vars map[string]string) []int {
+
+	found := make(map[int]bool)
+	for key := range vars {
+		if !strings.HasPrefix(key, name+EnvSeparator) {
+			continue
+		}
+		digits := key[len(name)+len(EnvSeparator):]
+		if at := strings.Index(digits, EnvSeparator); at >= 0 {
+			digits = digits[:at]
+		}
+		if index, err := strconv.Atoi(digits); err == nil && index >= 0 && strconv.Itoa(index) == digits {
+			found[index] = true
+		}
+	}
+
+	indexes := make([]int, 0, len(found))
+	for index := range found {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+func envName(keyname string) string {
+	return strings.ToUpper(strings.ReplaceAll(keyname, "-", EnvSeparator))
+}
+
+// Get slice converter, through pointers.
+func sliceOf(convert Convert) *Slice {
+	switch c := convert.(type) {
+	case *Slice:
+		return c
+	case *Ptr:
+		return sliceOf(c.Internal)
+	default:
+		return nil
+	}
+}
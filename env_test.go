@@ -0,0 +1,100 @@
+// env_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject"
+)
+
+type EnvShared struct {
+	Version float64 `map-to:"version"`
+}
+
+type envServer struct {
+	Host string `map-to:"host"`
+	Port int    `map-to:"port"`
+}
+
+type envConfig struct {
+	Server    envServer         `map-to:"server"`
+	Backends  []envServer       `map-to:"backends"`
+	Hosts     []string          `map-to:"hosts"`
+	Ports     []int             `map-to:"ports"`
+	Labels    map[string]string `map-to:"labels"`
+	LogLevel  string            `map-to:"log-level"`
+	Proxy     *envServer        `map-to:"proxy"`
+	EnvShared `map-to:"<-"`
+}
+
+func TestFromEnv(t *testing.T) {
+
+	compiled := convertobject.CompileStructForce(envConfig{})
+	src, err := compiled.FromEnv("APP", []string{
+		"APP_SERVER_HOST=localhost",
+		"APP_SERVER_PORT=8080",
+		"APP_BACKENDS_0_HOST=db",
+		"APP_BACKENDS_1_HOST=cache",
+		"APP_BACKENDS_1_PORT=6379",
+		"APP_HOSTS=a, b",
+		"APP_PORTS_0=1",
+		"APP_PORTS_1=2",
+		"APP_LABELS_TEAM=core",
+		"APP_LOG_LEVEL=debug",
+		"APP_VERSION=1.5",
+		"OTHER_SERVER_PORT=1",
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	dst := envConfig{}
+	if err := compiled.Convert(src, &dst, ""); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := envConfig{
+		Server:    envServer{Host: "localhost", Port: 8080},
+		Backends:  []envServer{{Host: "db"}, {Host: "cache", Port: 6379}},
+		Hosts:     []string{"a", "b"},
+		Ports:     []int{1, 2},
+		Labels:    map[string]string{"team": "core"},
+		LogLevel:  "debug",
+		EnvShared: EnvShared{Version: 1.5},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("want %+v, has %+v", want, dst)
+	}
+}
+
+func TestFromEnvGap(t *testing.T) {
+
+	compiled := convertobject.CompileStructForce(envConfig{})
+	for environ, want := range map[string]string{
+		"APP_PORTS_0=1 APP_PORTS_2=3":                   "ports[1] is required property, but cannot found it",
+		"APP_BACKENDS_1_HOST=db":                        "backends[0] is required property, but cannot found it",
+		"APP_BACKENDS_0_HOST=db APP_BACKENDS_2_PORT=80": "backends[1] is required property, but cannot found it",
+	} {
+		if _, err := compiled.FromEnv("APP", strings.Fields(environ)); err == nil {
+			t.Errorf("%s: want error", environ)
+		} else if err.Error() != want {
+			t.Errorf("%s: want %q, has %q", environ, want, err.Error())
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// flag.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+)
+
+const (
+	// Label option of the usage of the flag, for example `map-to:"port,desc=listening port"`.
+	OptionDesc = `desc`
+	// Label option of the default value of the flag, for example `map-to:"port,default=8080"`.
+	OptionDefault = `default`
+
+	// Separator of flag names, between keynames.
+	FlagSeparator = `.`
+)
+
+type (
+	// Flags registered for members of the structure, see BindFlags.
+	FlagBinding struct {
+		Struct  *Struct
+		FlagSet *flag.FlagSet
+		flags   []*boundFlag
+	}
+
+	boundFlag struct {
+		name     string
+		path     []string
+		defValue string
+		hasDef   bool
+		kind     reflect.Kind
+		values   []string
+	}
+)
+
+// Register flags of the structure's members on the flag set.
+//
+// Flag names are keynames of the member path joined with '.', such as `server.port`, following the prefix.
+// Usage and default value are given by desc and default label options.
+// Members of slices are set by repeating the flag or separating values with ',', and members of maps by `key=value`.
+// Slices of structures are not bound.
+func (s *Struct) BindFlags(fs *flag.FlagSet, prefix string) *FlagBinding {
+
+	binding := &FlagBinding{Struct: s, FlagSet: fs}
+	if len(prefix) > 0 && !strings.HasSuffix(prefix, FlagSeparator) {
+		prefix += FlagSeparator
+	}
+	binding.bindStruct(s, prefix, nil)
+	return binding
+}
+
+// Source map of flags which are explicitly set, to use as an override layer.
+func (b *FlagBinding) Source() map[string]interface{} {
+
+	result := make(map[string]interface{})
+	for _, bound := range b.setFlags() {
+		insertPath(result, bound.path, bound.source())
+	}
+	return result
+}
+
+// Source map of default values given by default label options, to use as the lowest layer.
+func (b *FlagBinding) Defaults() map[string]interface{} {
+
+	result := make(map[string]interface{})
+	for _, bound := range b.flags {
+		if bound.hasDef {
+			insertPath(result, bound.path, (&boundFlag{kind: bound.kind, values: splitFlagList(bound.defValue, bound.kind)}).source())
+		}
+	}
+	return result
+}
+
+// Layer of flags which are explicitly set, named "flags".
+func (b *FlagBinding) Layer() Layer {
+	return Layer{Name: "flags", Source: b.Source()}
+}
+
+// Convert flags which are explicitly set into the destination, members of others are left as they are.
+// Flags are converted as a partial source with ModeMerge, so that required, required_if and group checks are skipped,
+// and existing pointers and maps are converted into, while slices set by flags replace existing ones.
+func (b *FlagBinding) Apply(dst interface{}) error {
+
+	if destination := reflect.ValueOf(dst); destination.Kind() == reflect.Ptr && !destination.IsNil() {
+		for _, bound := range b.setFlags() {
+			if bound.kind == reflect.Slice {
+				resetPath(b.Struct, destination.Elem(), bound.path)
+			}
+		}
+	}
+	return b.Struct.ConvertWith(b.Source(), dst, "", Options{Mode: ModeMerge, Partial: true})
+}
+
+// Flags which are explicitly set, in order of binding.
+func (b *FlagBinding) setFlags() []*boundFlag {
+
+	set := make(map[string]bool)
+	b.FlagSet.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	result := []*boundFlag{}
+	for _, bound := range b.flags {
+		if set[bound.name] {
+			result = append(result, bound)
+		}
+	}
+	return result
+}
+
+func (b *FlagBinding) bindStruct(s *Struct, prefix string, path []string) {
+
+	for i := range s.Members {
+
+		member := &s.Members[i]
+		if member.Embed {
+			if embedded := structOf(member.Convert); embedded != nil {
+				b.bindStruct(embedded, prefix, path)
+			}
+			continue
+		}
+
+		name := prefix + member.Keyname
+		memPath := append(append([]string{}, path...), member.Keyname)
+		__type := s.Type.Field(member.MemberAt).Type
+		for __type.Kind() == reflect.Ptr {
+			__type = __type.Elem()
+		}
+
+		if internal := structOf(member.Convert); internal != nil {
+			b.bindStruct(internal, name+FlagSeparator, memPath)
+			continue
+		} else if slice := sliceOf(member.Convert); slice != nil && structOf(slice.Internal) != nil {
+			continue
+		}
+
+		kind := __type.Kind()
+		if isBytes(__type) {
+			kind = reflect.String
+		}
+		bound := &boundFlag{name: name, path: memPath, kind: kind}
+		bound.defValue, bound.hasDef = member.Options[OptionDefault]
+		b.flags = append(b.flags, bound)
+		b.FlagSet.Var(bound, name, member.Options[OptionDesc])
+	}
+}
+
+func (f *boundFlag) String() string {
+	if f == nil {
+		return ""
+	} else if len(f.values) == 0 {
+		return f.defValue
+	}
+	return strings.Join(f.values, EnvListSeparator)
+}
+
+func (f *boundFlag) Set(val string) error {
+	switch f.kind {
+	case reflect.Slice, reflect.Array:
+		f.values = append(f.values, splitFlagList(val, f.kind)...)
+	case reflect.Map:
+		if !strings.Contains(val, "=") {
+			return errors.New("want key=value, has " + val)
+		}
+		f.values = append(f.values, val)
+	default:
+		f.values = []string{val}
+	}
+	return nil
+}
+
+// Reports whether the flag is set without value, as -verbose.
+func (f *boundFlag) IsBoolFlag() bool {
+	return f.kind == reflect.Bool
+}
+
+func (f *boundFlag) source() interface{} {
+	switch f.kind {
+	case reflect.Slice, reflect.Array:
+		elems := make([]interface{}, len(f.values))
+		for i, val := range f.values {
+			elems[i] = val
+		}
+		return elems
+	case reflect.Map:
+		mapped := make(map[string]interface{}, len(f.values))
+		for _, val := range f.values {
+			if at := strings.Index(val, "="); at >= 0 {
+				mapped[val[:at]] = val[at+1:]
+			}
+		}
+		return mapped
+	default:
+		if len(f.values) == 0 {
+			return ""
+		}
+		return f.values[len(f.values)-1]
+	}
+}
+
+func splitFlagList(val string, kind reflect.Kind) []string {
+	switch kind {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		values := []string{}
+		for _, elem := range strings.Split(val, EnvListSeparator) {
+			if elem = strings.TrimSpace(elem); len(elem) > 0 {
+				values = append(values, elem)
+			}
+		}
+		return values
+	default:
+		return []string{val}
+	}
+}
+
+// Insert the value into nested maps along the path of keynames.
+func insertPath(dst map[string]interface{}, path []string, val interface{}) {
+	for _, keyname := range path[:len(path)-1] {
+		child, ok := dst[keyname].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			dst[keyname] = child
+		}
+		dst = child
+	}
+	dst[path[len(path)-1]] = val
+}
+
+// Reset the member along the path of keynames to zero, members behind nil pointers are already zero.
+func resetPath(s *Struct, val reflect.Value, path []string) {
+
+	for i := range s.Members {
+
+		member := &s.Members[i]
+		field := val.Field(member.MemberAt)
+		if !member.Embed && member.Keyname != path[0] {
+			continue
+		} else if !member.Embed && len(path) == 1 {
+			field.Set(reflect.Zero(field.Type()))
+			return
+		}
+
+		for field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+		if internal := structOf(member.Convert); internal != nil && field.Kind() == reflect.Struct {
+			if member.Embed {
+				resetPath(internal, field, path)
+			} else {
+				resetPath(internal, field, path[1:])
+			}
+		}
+		if !member.Embed {
+			return
+		}
+	}
+}
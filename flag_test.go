@@ -0,0 +1,110 @@
+// flag_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject"
+)
+
+type flagServer struct {
+	Host string `map-to:"host,desc=listening host,default=localhost"`
+	Port int    `map-to:"port,desc=listening port,default=8080"`
+}
+
+type flagConfig struct {
+	Server  flagServer        `map-to:"server"`
+	Verbose bool              `map-to:"verbose"`
+	Hosts   []string          `map-to:"hosts"`
+	Labels  map[string]string `map-to:"labels"`
+}
+
+func TestBindFlags(t *testing.T) {
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	binding := convertobject.CompileStructForce(flagConfig{}).BindFlags(fs, "")
+
+	if f := fs.Lookup("server.port"); f == nil {
+		t.Fatal("server.port is not registered")
+	} else if f.Usage != "listening port" || f.DefValue != "8080" {
+		t.Errorf("server.port has usage %q and default %q", f.Usage, f.DefValue)
+	}
+
+	if err := fs.Parse([]string{"-server.port", "9090", "-verbose", "-hosts", "a,b", "-hosts", "c", "-labels", "team=core"}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	dst := flagConfig{}
+	dst.Server.Host = "example.com"
+	if err := binding.Apply(&dst); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := flagConfig{Verbose: true, Hosts: []string{"a", "b", "c"}, Labels: map[string]string{"team": "core"}}
+	want.Server.Host, want.Server.Port = "example.com", 9090
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("want %+v, has %+v", want, dst)
+	}
+
+	defaults := binding.Defaults()
+	if server, ok := defaults["server"].(map[string]interface{}); !ok || server["host"] != "localhost" || server["port"] != "8080" {
+		t.Errorf("defaults: %v", defaults)
+	}
+}
+
+type flagSrv struct {
+	Host string `map-to:"host!"`
+	Port int    `map-to:"port"`
+}
+
+type flagRequired struct {
+	Name    string            `map-to:"name!"`
+	Cert    string            `map-to:"cert,required_if=tls"`
+	TLS     bool              `map-to:"tls"`
+	Token   string            `map-to:"token,group=auth,atleastone"`
+	Srv     *flagSrv          `map-to:"srv"`
+	Labels  map[string]string `map-to:"labels"`
+	Hosts   []string          `map-to:"hosts"`
+	Verbose bool              `map-to:"verbose"`
+}
+
+func TestFlagApplyPartial(t *testing.T) {
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	binding := convertobject.CompileStructForce(flagRequired{}).BindFlags(fs, "")
+	if err := fs.Parse([]string{"-srv.port", "9", "-tls", "-labels", "b=2", "-hosts", "c"}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	dst := flagRequired{Name: "name", Srv: &flagSrv{Host: "h", Port: 1}, Labels: map[string]string{"a": "1"}, Hosts: []string{"a", "b"}}
+	srv := dst.Srv
+	if err := binding.Apply(&dst); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := flagRequired{Name: "name", TLS: true, Srv: &flagSrv{Host: "h", Port: 9}, Labels: map[string]string{"a": "1", "b": "2"}, Hosts: []string{"c"}}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("want %+v, has %+v", want, dst)
+	} else if dst.Srv != srv {
+		t.Error("want the existing pointer reused")
+	}
+}
@@ -0,0 +1,348 @@
+// flat.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/streamwest-1629/convertobject/standard"
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+type (
+	// Segment of flat keys, keyname or slice index.
+	flatSegment struct {
+		name  string
+		index int
+	}
+
+	// Slice under construction, elements are keyed by indexes.
+	flatSlice map[int]interface{}
+)
+
+// Unflatten the map with dotted and bracket-indexed keys, such as `db.pool.max` and `hosts[0].name`,
+// into nested maps and slices which Struct.Convert accepts.
+// Indexes of each slice must be continuous from 0.
+func Unflatten(flat map[string]interface{}) (map[string]interface{}, error) {
+
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	root := make(map[string]interface{})
+	for _, key := range keys {
+
+		segments, err := parseFlatKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		var container interface{} = root
+		for i, segment := range segments {
+
+			last := i == len(segments)-1
+			var next interface{}
+			if !last {
+				if segments[i+1].index >= 0 {
+					next = flatSlice{}
+				} else {
+					next = map[string]interface{}{}
+				}
+			} else {
+				next = flat[key]
+			}
+
+			switch c := container.(type) {
+			case map[string]interface{}:
+				if segment.index >= 0 {
+					return nil, errors.New(key + ": index of non-slice")
+				}
+				if prev, exist := c[segment.name]; exist {
+					if last || !sameContainer(prev, next) {
+						return nil, errors.New(key + ": conflicts with other keys")
+					}
+					next = prev
+				} else {
+					c[segment.name] = next
+				}
+			case flatSlice:
+				if segment.index < 0 {
+					return nil, errors.New(key + ": keyname of slice")
+				}
+				if prev, exist := c[segment.index]; exist {
+					if last || !sameContainer(prev, next) {
+						return nil, errors.New(key + ": conflicts with other keys")
+					}
+					next = prev
+				} else {
+					c[segment.index] = next
+				}
+			}
+			container = next
+		}
+	}
+
+	result, err := finishFlat(root, "")
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]interface{}), nil
+}
+
+// Flatten the structure into the map with dotted and bracket-indexed keys, the inverse of Unflatten.
+// Values of enums are their names, and []byte are encoded with their encoding options.
+func Flatten(src interface{}) (map[string]interface{}, error) {
+
+	val := reflect.ValueOf(src)
+	for val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	compiled, err := CompileStruct(src)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	if err := flattenValue(compiled, val, "", result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func flattenValue(convert Convert, val reflect.Value, property string, result map[string]interface{}) error {
+
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if ptr, ok := convert.(*Ptr); ok {
+		return flattenValue(ptr.Internal, val, property, result)
+	}
+
+	switch c := convert.(type) {
+	case *Struct:
+		for i := range c.Members {
+			member := &c.Members[i]
+			memProperty := property
+			if !member.Embed {
+				memProperty = joinProperty(property, member.Keyname)
+			}
+			if err := flattenValue(member.Convert, val.Field(member.MemberAt), memProperty, result); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *Slice:
+		for i := 0; i < val.Len(); i++ {
+			if err := flattenValue(c.Internal, val.Index(i), property+"["+strconv.Itoa(i)+"]", result); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *Map:
+		flattenAny(val.Interface(), property, result)
+		return nil
+
+	case *Bytes:
+		buf := make([]byte, val.Len())
+		reflect.Copy(reflect.ValueOf(buf), val)
+		switch c.Encoding {
+		case standard.EncodingBase64:
+			result[property] = base64.StdEncoding.EncodeToString(buf)
+		case standard.EncodingHex:
+			result[property] = hex.EncodeToString(buf)
+		default:
+			result[property] = string(buf)
+		}
+		return nil
+
+	case *Scanner:
+		// sql.Null* types are their values, and absent when invalid
+		if valuer, ok := val.Interface().(driver.Valuer); !ok && c.Struct != nil {
+			return flattenValue(c.Struct, val, property, result)
+		} else if !ok {
+			if (val.Kind() != reflect.Slice && val.Kind() != reflect.Map) || !val.IsNil() {
+				result[property] = val.Interface()
+			}
+		} else if value, err := valuer.Value(); err != nil {
+			return util.ErrAtProperty(property, err)
+		} else if value != nil {
+			result[property] = value
+		}
+		return nil
+
+	case Encoder:
+		if encoded, err := c.Encode(val.Interface(), property); err != nil {
+			return err
+		} else {
+			result[property] = encoded
+		}
+		return nil
+
+	default:
+		// structures without compiled members, such as big.Int and time.Time, are formatted as texts
+		if val.Kind() == reflect.Struct {
+			if text, ok := flattenText(val); ok {
+				result[property] = text
+				return nil
+			}
+		}
+		result[property] = val.Interface()
+		return nil
+	}
+}
+
+func flattenText(val reflect.Value) (string, bool) {
+
+	candidates := []reflect.Value{val}
+	if val.CanAddr() {
+		candidates = append(candidates, val.Addr())
+	}
+	for _, candidate := range candidates {
+		if marshaler, ok := candidate.Interface().(encoding.TextMarshaler); ok {
+			if text, err := marshaler.MarshalText(); err == nil {
+				return string(text), true
+			}
+		} else if stringer, ok := candidate.Interface().(fmt.Stringer); ok {
+			return stringer.String(), true
+		}
+	}
+	return "", false
+}
+
+// Flatten values without compiled converters, such as map[string]interface{} members.
+func flattenAny(src interface{}, property string, result map[string]interface{}) {
+
+	val := reflect.ValueOf(src)
+	switch {
+	case !val.IsValid():
+		return
+	case val.Kind() == reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			flattenAny(iter.Value().Interface(), joinProperty(property, fmt.Sprint(iter.Key().Interface())), result)
+		}
+	case val.Kind() == reflect.Slice && val.Type().Elem().Kind() != reflect.Uint8:
+		for i := 0; i < val.Len(); i++ {
+			flattenAny(val.Index(i).Interface(), property+"["+strconv.Itoa(i)+"]", result)
+		}
+	default:
+		result[property] = src
+	}
+}
+
+func parseFlatKey(key string) ([]flatSegment, error) {
+
+	segments := []flatSegment{}
+	for _, part := range strings.Split(key, ".") {
+
+		name := part
+		indexes := []int{}
+		if at := strings.IndexByte(part, '['); at >= 0 {
+			name = part[:at]
+			rest := part[at:]
+			for len(rest) > 0 {
+				end := strings.IndexByte(rest, ']')
+				if rest[0] != '[' || end < 0 {
+					return nil, errors.New(key + ": invalid index")
+				}
+				index, err := strconv.Atoi(rest[1:end])
+				if err != nil || index < 0 {
+					return nil, errors.New(key + ": invalid index")
+				}
+				indexes = append(indexes, index)
+				rest = rest[end+1:]
+			}
+		}
+
+		if len(name) == 0 && (len(segments) > 0 || len(indexes) == 0) {
+			return nil, errors.New(key + ": empty keyname")
+		} else if len(name) > 0 {
+			segments = append(segments, flatSegment{name: name, index: -1})
+		}
+		for _, index := range indexes {
+			segments = append(segments, flatSegment{index: index})
+		}
+	}
+
+	if len(segments) == 0 || segments[0].index >= 0 {
+		return nil, errors.New(key + ": must start with keyname")
+	}
+	return segments, nil
+}
+
+func sameContainer(a, b interface{}) bool {
+	switch a.(type) {
+	case map[string]interface{}:
+		_, ok := b.(map[string]interface{})
+		return ok
+	case flatSlice:
+		_, ok := b.(flatSlice)
+		return ok
+	default:
+		return false
+	}
+}
+
+// Replace slices under construction with []interface{}.
+func finishFlat(val interface{}, property string) (interface{}, error) {
+
+	switch c := val.(type) {
+	case map[string]interface{}:
+		for key, elem := range c {
+			if finished, err := finishFlat(elem, joinProperty(property, key)); err != nil {
+				return nil, err
+			} else {
+				c[key] = finished
+			}
+		}
+		return c, nil
+
+	case flatSlice:
+		result := make([]interface{}, len(c))
+		for i := range result {
+			elem, exist := c[i]
+			if !exist {
+				return nil, util.ErrCannotFound(property + "[" + strconv.Itoa(i) + "]")
+			}
+			if finished, err := finishFlat(elem, property+"["+strconv.Itoa(i)+"]"); err != nil {
+				return nil, err
+			} else {
+				result[i] = finished
+			}
+		}
+		return result, nil
+
+	default:
+		return val, nil
+	}
+}
@@ -0,0 +1,160 @@
+// flat_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject"
+)
+
+type flatLevel int
+
+func (flatLevel) EnumNames() map[string]interface{} {
+	return map[string]interface{}{
+		"debug": flatLevel(0),
+		"info":  flatLevel(1),
+	}
+}
+
+type flatPool struct {
+	Max  int `map-to:"max"`
+	Idle int `map-to:"idle"`
+}
+
+type flatHost struct {
+	Name string `map-to:"name"`
+	Port int    `map-to:"port"`
+}
+
+type flatConfig struct {
+	Pool   flatPool          `map-to:"pool"`
+	Hosts  []flatHost        `map-to:"hosts"`
+	Tags   []string          `map-to:"tags"`
+	Labels map[string]string `map-to:"labels"`
+	Level  flatLevel         `map-to:"level"`
+	Secret []byte            `map-to:"secret,encoding=hex"`
+	Limit  *int              `map-to:"limit"`
+}
+
+func TestUnflatten(t *testing.T) {
+
+	flat := map[string]interface{}{
+		"pool.max":       "10",
+		"pool.idle":      "2",
+		"hosts[0].name":  "a",
+		"hosts[0].port":  "80",
+		"hosts[1].name":  "b",
+		"tags[0]":        "x",
+		"tags[1]":        "y",
+		"labels.env":     "prod",
+		"level":          "info",
+		"secret":         "cafe",
+		"matrix[1][0]":   "c",
+		"matrix[0][0]":   "a",
+		"matrix[0][1]":   "b",
+		"deep.a.b.c.d.e": true,
+	}
+
+	nested, err := convertobject.Unflatten(flat)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	wantMatrix := []interface{}{[]interface{}{"a", "b"}, []interface{}{"c"}}
+	if !reflect.DeepEqual(nested["matrix"], wantMatrix) {
+		t.Errorf("matrix: %#v", nested["matrix"])
+	}
+
+	dst := flatConfig{}
+	if err := convertobject.DirectConvert(nested, &dst); err != nil {
+		t.Fatal(err.Error())
+	}
+	want := flatConfig{
+		Pool:   flatPool{Max: 10, Idle: 2},
+		Hosts:  []flatHost{{Name: "a", Port: 80}, {Name: "b"}},
+		Tags:   []string{"x", "y"},
+		Labels: map[string]string{"env": "prod"},
+		Level:  flatLevel(1),
+		Secret: []byte{0xca, 0xfe},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("unexpected result: %#v", dst)
+	}
+}
+
+func TestUnflattenError(t *testing.T) {
+
+	cases := []map[string]interface{}{
+		{"a": 1, "a.b": 2},
+		{"a[0]": 1, "a.b": 2},
+		{"a[1]": 1},
+		{"a[x]": 1},
+		{"a..b": 1},
+		{"[0]": 1},
+	}
+	for _, flat := range cases {
+		if _, err := convertobject.Unflatten(flat); err == nil {
+			t.Errorf("%v: must be error", flat)
+		}
+	}
+}
+
+func TestFlatten(t *testing.T) {
+
+	limit := 5
+	src := flatConfig{
+		Pool:   flatPool{Max: 10, Idle: 2},
+		Hosts:  []flatHost{{Name: "a", Port: 80}},
+		Tags:   []string{"x"},
+		Labels: map[string]string{"env": "prod"},
+		Level:  flatLevel(1),
+		Secret: []byte{0xca, 0xfe},
+		Limit:  &limit,
+	}
+
+	flat, err := convertobject.Flatten(&src)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	want := map[string]interface{}{
+		"pool.max":      10,
+		"pool.idle":     2,
+		"hosts[0].name": "a",
+		"hosts[0].port": 80,
+		"tags[0]":       "x",
+		"labels.env":    "prod",
+		"level":         "info",
+		"secret":        "cafe",
+		"limit":         5,
+	}
+	if !reflect.DeepEqual(flat, want) {
+		t.Errorf("unexpected result: %#v", flat)
+	}
+
+	// round trip
+	nested, err := convertobject.Unflatten(flat)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	dst := flatConfig{}
+	if err := convertobject.DirectConvert(nested, &dst); err != nil {
+		t.Fatal(err.Error())
+	} else if !reflect.DeepEqual(dst, src) {
+		t.Errorf("round trip: %#v", dst)
+	}
+}
@@ -0,0 +1,378 @@
+// format/cbor/cbor.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cbor decodes CBOR (RFC 8949) into values convertible by convertobject.
+//
+// Maps are map[interface{}]interface{} whose integer keys are int64, so that members labeled with numbers,
+// as `map-to:"1"`, are converted from them. Unsigned integers are int64, or uint64 beyond the range of int64,
+// and negative integers beyond the range of int64 are *big.Int.
+// Floats of all sizes are float64, byte strings are []byte, text strings are string and arrays are []interface{}.
+// Null and undefined are nil. Tags 0 and 1 are time.Time, tags 2 and 3 are *big.Int,
+// and other tags are ignored with their contents decoded.
+package cbor
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/streamwest-1629/convertobject"
+	"github.com/streamwest-1629/convertobject/format"
+)
+
+const (
+	// Maximum depth of nested arrays, maps and tags.
+	MaxDepth = 512
+)
+
+type (
+	decoder struct {
+		data  []byte
+		pos   int
+		depth int
+	}
+)
+
+const (
+	majorUnsigned = iota
+	majorNegative
+	majorBytes
+	majorText
+	majorArray
+	majorMap
+	majorTag
+	majorSimple
+)
+
+// indefinite-length marker of additional information
+const indefinite = -1
+
+// Decode the CBOR data item, which must be the whole of the data.
+func Unmarshal(data []byte) (interface{}, error) {
+
+	d := &decoder{data: data}
+	val, err := d.decode()
+	if err != nil {
+		return nil, err
+	} else if d.pos < len(d.data) {
+		return nil, d.fail("unexpected data after the item")
+	}
+	return val, nil
+}
+
+// Decode the CBOR data item, and convert it into the destination.
+func Decode(data []byte, dst interface{}) error {
+
+	if src, err := Unmarshal(data); err != nil {
+		return err
+	} else {
+		return convertobject.DirectConvert(src, dst)
+	}
+}
+
+func (d *decoder) fail(msg string) error {
+	return &format.OffsetError{Offset: d.pos, Msg: msg}
+}
+
+func (d *decoder) failAt(pos int, msg string) error {
+	return &format.OffsetError{Offset: pos, Msg: msg}
+}
+
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, d.fail("unexpected end of data")
+	}
+	buf := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return buf, nil
+}
+
+// Read the initial byte and its argument.
+// The argument is indefinite for indefinite-length items, and raw bits for floats.
+func (d *decoder) head() (major int, info byte, arg uint64, isIndefinite bool, err error) {
+
+	if d.pos >= len(d.data) {
+		return 0, 0, 0, false, d.fail("unexpected end of data")
+	}
+	initial := d.data[d.pos]
+	d.pos++
+	major, info = int(initial>>5), initial&0x1f
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), false, nil
+	case info <= 27:
+		buf, err := d.read(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+		switch len(buf) {
+		case 1:
+			arg = uint64(buf[0])
+		case 2:
+			arg = uint64(binary.BigEndian.Uint16(buf))
+		case 4:
+			arg = uint64(binary.BigEndian.Uint32(buf))
+		default:
+			arg = binary.BigEndian.Uint64(buf)
+		}
+		return major, info, arg, false, nil
+	case info == 31 && major != majorUnsigned && major != majorNegative && major != majorTag:
+		return major, info, 0, true, nil
+	default:
+		return 0, 0, 0, false, d.failAt(d.pos-1, "invalid additional information "+strconv.Itoa(int(info)))
+	}
+}
+
+func (d *decoder) decode() (interface{}, error) {
+
+	if d.depth >= MaxDepth {
+		return nil, d.fail("too deeply nested")
+	}
+	d.depth++
+	defer func() { d.depth-- }()
+
+	start := d.pos
+	major, info, arg, isIndefinite, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUnsigned:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+
+	case majorNegative:
+		if arg > math.MaxInt64 {
+			val := new(big.Int).SetUint64(arg)
+			return val.Neg(val).Sub(val, big.NewInt(1)), nil
+		}
+		return -1 - int64(arg), nil
+
+	case majorBytes, majorText:
+		buf, err := d.decodeString(major, arg, isIndefinite)
+		if err != nil {
+			return nil, err
+		} else if major == majorBytes {
+			return buf, nil
+		} else if !utf8.Valid(buf) {
+			return nil, d.failAt(start, "text string is not valid UTF-8")
+		}
+		return string(buf), nil
+
+	case majorArray:
+		return d.decodeArray(arg, isIndefinite)
+
+	case majorMap:
+		return d.decodeMap(arg, isIndefinite)
+
+	case majorTag:
+		return d.decodeTag(start, arg)
+
+	default:
+		return d.decodeSimple(start, info, arg, isIndefinite)
+	}
+}
+
+func (d *decoder) decodeString(major int, length uint64, isIndefinite bool) ([]byte, error) {
+
+	if !isIndefinite {
+		buf, err := d.read(length)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, buf...), nil
+	}
+
+	// chunks of definite-length strings of the same major type
+	result := []byte{}
+	for {
+		if d.pos < len(d.data) && d.data[d.pos] == 0xff {
+			d.pos++
+			return result, nil
+		}
+		start := d.pos
+		chunkMajor, _, chunkLength, chunkIndefinite, err := d.head()
+		if err != nil {
+			return nil, err
+		} else if chunkMajor != major || chunkIndefinite {
+			return nil, d.failAt(start, "invalid chunk of indefinite-length string")
+		}
+		buf, err := d.read(chunkLength)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, buf...)
+	}
+}
+
+func (d *decoder) decodeArray(length uint64, isIndefinite bool) ([]interface{}, error) {
+
+	result := make([]interface{}, 0, d.capacity(length, isIndefinite))
+	for i := uint64(0); isIndefinite || i < length; i++ {
+		if isIndefinite && d.pos < len(d.data) && d.data[d.pos] == 0xff {
+			d.pos++
+			break
+		}
+		if val, err := d.decode(); err != nil {
+			return nil, err
+		} else {
+			result = append(result, val)
+		}
+	}
+	return result, nil
+}
+
+func (d *decoder) decodeMap(length uint64, isIndefinite bool) (map[interface{}]interface{}, error) {
+
+	result := make(map[interface{}]interface{}, d.capacity(length, isIndefinite)/2)
+	for i := uint64(0); isIndefinite || i < length; i++ {
+		if isIndefinite && d.pos < len(d.data) && d.data[d.pos] == 0xff {
+			d.pos++
+			break
+		}
+
+		start := d.pos
+		key, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		switch k := key.(type) {
+		case int64, uint64, string, bool, float64, nil:
+		case []byte:
+			key = string(k)
+		default:
+			return nil, d.failAt(start, "map key must be integer, string, bool or float")
+		}
+		if _, exist := result[key]; exist {
+			return nil, d.failAt(start, "duplicate map key")
+		}
+
+		if val, err := d.decode(); err != nil {
+			return nil, err
+		} else {
+			result[key] = val
+		}
+	}
+	return result, nil
+}
+
+// Capacity to allocate for the items, limited by the rest of the data.
+func (d *decoder) capacity(length uint64, isIndefinite bool) int {
+	if isIndefinite {
+		return 0
+	} else if rest := uint64(len(d.data) - d.pos); length > rest {
+		return int(rest)
+	}
+	return int(length)
+}
+
+func (d *decoder) decodeTag(start int, tag uint64) (interface{}, error) {
+
+	content, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case 0:
+		if str, ok := content.(string); !ok {
+			return nil, d.failAt(start, "date-time string must be text string")
+		} else if val, err := time.Parse(time.RFC3339Nano, str); err != nil {
+			return nil, d.failAt(start, "invalid date-time string: "+str)
+		} else {
+			return val, nil
+		}
+
+	case 1:
+		switch val := content.(type) {
+		case int64:
+			return time.Unix(val, 0).UTC(), nil
+		case float64:
+			if math.IsNaN(val) || math.IsInf(val, 0) {
+				return nil, d.failAt(start, "invalid epoch-based date-time")
+			}
+			sec, frac := math.Modf(val)
+			return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+		default:
+			return nil, d.failAt(start, "epoch-based date-time must be integer or float")
+		}
+
+	case 2, 3:
+		buf, ok := content.([]byte)
+		if !ok {
+			return nil, d.failAt(start, "bignum must be byte string")
+		}
+		val := new(big.Int).SetBytes(buf)
+		if tag == 3 {
+			val.Neg(val).Sub(val, big.NewInt(1))
+		}
+		return val, nil
+
+	default:
+		return content, nil
+	}
+}
+
+func (d *decoder) decodeSimple(start int, info byte, arg uint64, isIndefinite bool) (interface{}, error) {
+
+	switch {
+	case isIndefinite:
+		return nil, d.failAt(start, "unexpected break")
+	case info == 20:
+		return false, nil
+	case info == 21:
+		return true, nil
+	case info == 22 || info == 23:
+		return nil, nil
+	case info == 25:
+		return halfFloat(uint16(arg)), nil
+	case info == 26:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case info == 27:
+		return math.Float64frombits(arg), nil
+	default:
+		return nil, d.failAt(start, "unsupported simple value "+strconv.FormatUint(arg, 10))
+	}
+}
+
+// Convert IEEE 754 half-precision bits to float64.
+func halfFloat(bits uint16) float64 {
+
+	exp, mant := int(bits>>10)&0x1f, float64(bits&0x3ff)
+	var val float64
+	switch exp {
+	case 0:
+		val = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			val = math.Inf(1)
+		} else {
+			val = math.NaN()
+		}
+	default:
+		val = math.Ldexp(mant+1024, exp-25)
+	}
+	if bits&0x8000 != 0 {
+		return -val
+	}
+	return val
+}
@@ -0,0 +1,47 @@
+// format/cbor/cbor_fuzz_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package cbor_test
+
+import (
+	"testing"
+
+	"github.com/streamwest-1629/convertobject/format/cbor"
+)
+
+func FuzzUnmarshal(f *testing.F) {
+
+	for _, seed := range []string{
+		"a3016774656d702d30310282f94d60f9458003f5",
+		"bf61610161629f0203ffff",
+		"5f42010243030405ff",
+		"c249010000000000000000",
+		"c11a514b67b0",
+		"9bffffffffffffffff",
+	} {
+		f.Add(mustDecodeHex(f, seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// malformed input must be reported as errors, without panics
+		if _, err := cbor.Unmarshal(data); err == nil {
+			dst := cborReading{}
+			_ = cbor.Decode(data, &dst)
+		}
+	})
+}
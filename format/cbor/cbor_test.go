@@ -0,0 +1,142 @@
+// format/cbor/cbor_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cbor_test
+
+import (
+	"encoding/hex"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/streamwest-1629/convertobject/format/cbor"
+)
+
+type cborReading struct {
+	Sensor string    `map-to:"1!"`
+	Values []float64 `map-to:"2"`
+	Online bool      `map-to:"3"`
+}
+
+func mustDecodeHex(t testing.TB, str string) []byte {
+	buf, err := hex.DecodeString(str)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return buf
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	// examples of RFC 8949 Appendix A
+	cases := map[string]interface{}{
+		"00":                         int64(0),
+		"1903e8":                     int64(1000),
+		"1bffffffffffffffff":         uint64(math.MaxUint64),
+		"3863":                       int64(-100),
+		"3bffffffffffffffff":         new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 64)),
+		"c249010000000000000000":     new(big.Int).Lsh(big.NewInt(1), 64),
+		"f93c00":                     1.0,
+		"f9c400":                     -4.0,
+		"f90001":                     5.960464477539063e-08,
+		"fa47c35000":                 100000.0,
+		"fb3ff199999999999a":         1.1,
+		"f4":                         false,
+		"f5":                         true,
+		"f6":                         nil,
+		"f7":                         nil,
+		"4401020304":                 []byte{1, 2, 3, 4},
+		"6449455446":                 "IETF",
+		"62c3bc":                     "ü",
+		"83010203":                   []interface{}{int64(1), int64(2), int64(3)},
+		"a201020304":                 map[interface{}]interface{}{int64(1): int64(2), int64(3): int64(4)},
+		"a26161016162820203":         map[interface{}]interface{}{"a": int64(1), "b": []interface{}{int64(2), int64(3)}},
+		"5f42010243030405ff":         []byte{1, 2, 3, 4, 5},
+		"7f657374726561646d696e67ff": "streaming",
+		"9f018202039f0405ffff":       []interface{}{int64(1), []interface{}{int64(2), int64(3)}, []interface{}{int64(4), int64(5)}},
+		"bf61610161629f0203ffff":     map[interface{}]interface{}{"a": int64(1), "b": []interface{}{int64(2), int64(3)}},
+		"c074323031332d30332d32315432303a30343a30305a": time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC),
+		"c11a514b67b0": time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC),
+		"d74401020304": []byte{1, 2, 3, 4},
+	}
+
+	for input, want := range cases {
+		has, err := cbor.Unmarshal(mustDecodeHex(t, input))
+		if err != nil {
+			t.Errorf("%s: %s", input, err.Error())
+		} else if wantTime, ok := want.(time.Time); ok {
+			if hasTime, ok := has.(time.Time); !ok || !hasTime.Equal(wantTime) {
+				t.Errorf("%s: want %v, has %v", input, want, has)
+			}
+		} else if wantBig, ok := want.(*big.Int); ok {
+			if hasBig, ok := has.(*big.Int); !ok || hasBig.Cmp(wantBig) != 0 {
+				t.Errorf("%s: want %v, has %v", input, want, has)
+			}
+		} else if !reflect.DeepEqual(has, want) {
+			t.Errorf("%s: want %#v, has %#v", input, want, has)
+		}
+	}
+}
+
+func TestUnmarshalError(t *testing.T) {
+
+	cases := map[string]string{
+		"":                   "offset 0: unexpected end of data",
+		"1a0102":             "offset 1: unexpected end of data",
+		"0000":               "offset 1: unexpected data after the item",
+		"1c":                 "offset 0: invalid additional information 28",
+		"9bffffffffffffffff": "offset 9: unexpected end of data",
+		"a20102":             "offset 3: unexpected end of data",
+		"a201020103":         "offset 3: duplicate map key",
+		"a1800102":           "offset 1: map key must be integer, string, bool or float",
+		"62c328":             "offset 0: text string is not valid UTF-8",
+		"5f4101610262ff":     "offset 3: invalid chunk of indefinite-length string",
+		"ff":                 "offset 0: unexpected break",
+		"f8ff":               "offset 0: unsupported simple value 255",
+		"c06131":             "offset 0: invalid date-time string: 1",
+	}
+	for input, want := range cases {
+		if _, err := cbor.Unmarshal(mustDecodeHex(t, input)); err == nil {
+			t.Errorf("%s: must be error", input)
+		} else if err.Error() != want {
+			t.Errorf("%s: want %q, has %q", input, want, err.Error())
+		}
+	}
+
+	// deeply nested arrays
+	nested := make([]byte, cbor.MaxDepth+1)
+	for i := range nested {
+		nested[i] = 0x81
+	}
+	if _, err := cbor.Unmarshal(append(nested, 0x00)); err == nil {
+		t.Error("deeply nested arrays must be error")
+	}
+}
+
+func TestDecode(t *testing.T) {
+
+	// {1: "temp-01", 2: [21.5, 5.5], 3: true}
+	input := mustDecodeHex(t, "a3016774656d702d30310282f94d60f9458003f5")
+	dst := cborReading{}
+	if err := cbor.Decode(input, &dst); err != nil {
+		t.Fatal(err.Error())
+	}
+	want := cborReading{Sensor: "temp-01", Values: []float64{21.5, 5.5}, Online: true}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("unexpected result: %#v", dst)
+	}
+}
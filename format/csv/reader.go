@@ -0,0 +1,177 @@
+// format/csv/reader.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package csv reads CSV records into structures labeled with `map-to`.
+//
+// With header, columns are mapped to members whose keynames are the same as header names.
+// Without header, columns are mapped by their indexes to members whose keynames are integers, as `map-to:"0"`.
+// Empty cells are treated as absent, so that required members report them.
+package csv
+
+import (
+	stdcsv "encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/streamwest-1629/convertobject"
+)
+
+type (
+	// Streaming reader which converts each record into the structure.
+	Reader struct {
+		Struct *convertobject.Struct
+		// The underlying reader, configure Comma, Comment and so on before reading records.
+		CSV *stdcsv.Reader
+		// Options of converting records.
+		Options convertobject.Options
+
+		header  bool
+		columns []string
+	}
+
+	// The error of the record, with the line number where the record starts.
+	RowError struct {
+		Line int
+		Err  error
+	}
+)
+
+// Create reader which converts records into the structure type of target.
+// When header is true, the first record is read as header names.
+func NewReader(r io.Reader, target interface{}, header bool) (*Reader, error) {
+
+	compiled, err := convertobject.CompileStruct(target)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := stdcsv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	return &Reader{
+		Struct: compiled,
+		CSV:    reader,
+		header: header,
+	}, nil
+}
+
+// Header names read from the first record, nil when the reader has no header.
+func (r *Reader) Header() ([]string, error) {
+	if err := r.readHeader(); err != nil {
+		return nil, err
+	}
+	return r.columns, nil
+}
+
+// Read the next record into dst, which is the pointer of the structure.
+// Returns io.EOF when no records remain. Conversion errors and CSV parse errors are *RowError,
+// and reading can be continued after them.
+func (r *Reader) Read(dst interface{}) error {
+
+	if err := r.readHeader(); err != nil {
+		return err
+	}
+
+	record, err := r.CSV.Read()
+	if err == io.EOF {
+		return io.EOF
+	} else if parseErr, ok := err.(*stdcsv.ParseError); ok {
+		return &RowError{Line: parseErr.StartLine, Err: err}
+	} else if err != nil {
+		return err
+	}
+
+	var src interface{}
+	if r.header {
+		mapped := make(map[string]interface{}, len(record))
+		for i, cell := range record {
+			if i < len(r.columns) && len(cell) > 0 {
+				mapped[r.columns[i]] = cell
+			}
+		}
+		src = mapped
+	} else {
+		mapped := make(map[int64]interface{}, len(record))
+		for i, cell := range record {
+			if len(cell) > 0 {
+				mapped[int64(i)] = cell
+			}
+		}
+		src = mapped
+	}
+
+	if err := r.Struct.ConvertWith(src, dst, "", r.Options); err != nil {
+		return &RowError{Line: r.line(), Err: err}
+	}
+	return nil
+}
+
+// Read all remaining records, appending them to dst which is the pointer of the slice of the structure.
+// Records with errors are skipped, and returned errors are all *RowError.
+func (r *Reader) ReadAll(dst interface{}) (errs []error) {
+
+	slice := reflect.ValueOf(dst)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice || slice.Elem().Type().Elem() != r.Struct.Type {
+		return []error{errors.New("destination must be the pointer of the slice of the structure")}
+	}
+	slice = slice.Elem()
+
+	for {
+		record := reflect.New(r.Struct.Type)
+		if err := r.Read(record.Interface()); err == io.EOF {
+			return errs
+		} else if rowErr, ok := err.(*RowError); ok {
+			errs = append(errs, rowErr)
+		} else if err != nil {
+			return append(errs, err)
+		} else {
+			slice.Set(reflect.Append(slice, record.Elem()))
+		}
+	}
+}
+
+func (r *Reader) readHeader() error {
+
+	if !r.header || r.columns != nil {
+		return nil
+	}
+
+	if record, err := r.CSV.Read(); err == io.EOF {
+		r.columns = []string{}
+		return nil
+	} else if parseErr, ok := err.(*stdcsv.ParseError); ok {
+		return &RowError{Line: parseErr.StartLine, Err: err}
+	} else if err != nil {
+		return err
+	} else {
+		r.columns = record
+		return nil
+	}
+}
+
+// Line where the record read last starts, call it only after the record is read successfully.
+func (r *Reader) line() int {
+	line, _ := r.CSV.FieldPos(0)
+	return line
+}
+
+func (e *RowError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
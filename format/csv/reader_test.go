@@ -0,0 +1,100 @@
+// format/csv/reader_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject/format/csv"
+)
+
+type employee struct {
+	Name   string  `map-to:"name!"`
+	Age    int     `map-to:"age"`
+	Salary float64 `map-to:"salary"`
+	Active bool    `map-to:"active"`
+}
+
+type point struct {
+	X int `map-to:"0!"`
+	Y int `map-to:"1!"`
+}
+
+func TestReadAllWithHeader(t *testing.T) {
+
+	input := "name,age,salary,active,unknown\n" +
+		"Ada,36,1200.5,yes,x\n" +
+		"\"Grace\nHopper\",abc,,no,\n" +
+		",40,1,true,\n" +
+		"Alan,41,,,\n"
+
+	reader, err := csv.NewReader(strings.NewReader(input), employee{}, true)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	records := []employee{}
+	errs := reader.ReadAll(&records)
+
+	want := []employee{{Name: "Ada", Age: 36, Salary: 1200.5, Active: true}, {Name: "Alan", Age: 41}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("want %+v, has %+v", want, records)
+	}
+
+	lines := []int{}
+	for _, err := range errs {
+		lines = append(lines, err.(*csv.RowError).Line)
+	}
+	if want := []int{3, 5}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("want errors at lines %v, has %v", want, errs)
+	}
+}
+
+func TestReadParseError(t *testing.T) {
+
+	reader, err := csv.NewReader(strings.NewReader("name\na\"b\nok\n"), employee{}, true)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	records := []employee{}
+	errs := reader.ReadAll(&records)
+	if want := []employee{{Name: "ok"}}; !reflect.DeepEqual(records, want) {
+		t.Errorf("want %+v, has %+v", want, records)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("want an error, has %v", errs)
+	} else if rowErr, ok := errs[0].(*csv.RowError); !ok || rowErr.Line != 2 {
+		t.Errorf("want error at line 2, has %v", errs[0])
+	}
+}
+
+func TestReadWithoutHeader(t *testing.T) {
+
+	reader, err := csv.NewReader(strings.NewReader("1,2\n3,4\n"), point{}, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	records := []point{}
+	if errs := reader.ReadAll(&records); len(errs) > 0 {
+		t.Fatal(errs)
+	} else if want := []point{{1, 2}, {3, 4}}; !reflect.DeepEqual(records, want) {
+		t.Errorf("want %+v, has %+v", want, records)
+	}
+}
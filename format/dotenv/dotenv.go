@@ -0,0 +1,321 @@
+// format/dotenv/dotenv.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dotenv parses .env files into maps convertible by convertobject.
+//
+// Each line is `KEY=value`, optionally prefixed with `export`. Lines starting with '#' are comments,
+// and so are ones following whitespace in unquoted values.
+// Values quoted with single quotes are literal. Values quoted with double quotes may span lines, and accept escapes \n, \t, \r, \", \\ and \$.
+// Unquoted and double-quoted values expand ${VAR}, $VAR and ${VAR:-default},
+// with variables defined above in the file, or ones given by the lookup function.
+package dotenv
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/streamwest-1629/convertobject"
+	"github.com/streamwest-1629/convertobject/format"
+)
+
+// Parse .env from the reader, filename is used in errors.
+// Variables not defined in the file are expanded with os.LookupEnv.
+func Parse(r io.Reader, filename string) (map[string]interface{}, error) {
+	return ParseLookup(r, filename, os.LookupEnv)
+}
+
+// Parse .env from the reader, variables not defined in the file are expanded with lookup.
+// lookup may be nil, then undefined variables are expanded to empty strings.
+func ParseLookup(r io.Reader, filename string, lookup func(name string) (string, bool)) (map[string]interface{}, error) {
+
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{
+		src:      string(bytes.TrimPrefix(buf, []byte("\ufeff"))),
+		filename: filename,
+		line:     1,
+		lookup:   lookup,
+		vars:     make(map[string]string),
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{}, len(p.vars))
+	for key, val := range p.vars {
+		result[key] = val
+	}
+	return result, nil
+}
+
+// Parse .env file of the path.
+func ParseFile(path string) (map[string]interface{}, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file, path)
+}
+
+// Parse .env from the reader, and convert it into the destination.
+// Members' keynames are variable names, as `map-to:"DATABASE_URL"`.
+func Decode(r io.Reader, filename string, dst interface{}) error {
+
+	if src, err := Parse(r, filename); err != nil {
+		return err
+	} else {
+		return convertobject.DirectConvert(src, dst)
+	}
+}
+
+// Format variables as os.Environ, to be given to convertobject.Struct.FromEnv.
+func Environ(vars map[string]interface{}) []string {
+
+	environ := make([]string, 0, len(vars))
+	for key, val := range vars {
+		if str, ok := val.(string); ok {
+			environ = append(environ, key+"="+str)
+		}
+	}
+	sort.Strings(environ)
+	return environ
+}
+
+type parser struct {
+	src      string
+	pos      int
+	filename string
+	line     int
+	lookup   func(name string) (string, bool)
+	vars     map[string]string
+}
+
+func (p *parser) fail(msg string) error {
+	return &format.ParseError{File: p.filename, Line: p.line, Msg: msg}
+}
+
+func (p *parser) parse() error {
+
+	for p.pos < len(p.src) {
+
+		p.skipSpaces()
+		if p.pos >= len(p.src) {
+			break
+		} else if c := p.src[p.pos]; c == '\n' {
+			p.pos++
+			p.line++
+			continue
+		} else if c == '#' {
+			p.skipLine()
+			continue
+		}
+
+		key := p.readKey()
+		if key == "export" {
+			p.skipSpaces()
+			if p.pos < len(p.src) && p.src[p.pos] != '=' {
+				key = p.readKey()
+			}
+		}
+		if len(key) == 0 {
+			return p.fail("want variable name, has " + strings.SplitN(p.src[p.pos:], "\n", 2)[0])
+		}
+
+		p.skipSpaces()
+		if p.pos >= len(p.src) || p.src[p.pos] != '=' {
+			return p.fail("want '=' after " + key)
+		}
+		p.pos++
+		p.skipSpaces()
+
+		startLine := p.line
+		if val, err := p.readValue(); err != nil {
+			return err
+		} else {
+			p.vars[key] = val
+		}
+
+		// rest of the line must be empty or a comment
+		p.skipSpaces()
+		if p.pos < len(p.src) && p.src[p.pos] != '\n' {
+			if p.src[p.pos] != '#' {
+				p.line = startLine
+				return p.fail("unexpected characters after value of " + key)
+			}
+			p.skipLine()
+		}
+	}
+	return nil
+}
+
+func (p *parser) readKey() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		if c := p.src[p.pos]; c == '_' || c == '.' || c == '-' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') {
+			p.pos++
+		} else {
+			break
+		}
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *parser) readValue() (string, error) {
+
+	if p.pos >= len(p.src) {
+		return "", nil
+	}
+
+	switch p.src[p.pos] {
+	case '\'':
+		end := strings.IndexByte(p.src[p.pos+1:], '\'')
+		if end < 0 {
+			return "", p.fail("value is not closed with '")
+		}
+		val := p.src[p.pos+1 : p.pos+1+end]
+		p.line += strings.Count(val, "\n")
+		p.pos += end + 2
+		return val, nil
+
+	case '"':
+		var builder strings.Builder
+		startLine := p.line
+		for p.pos++; p.pos < len(p.src); p.pos++ {
+			switch c := p.src[p.pos]; c {
+			case '"':
+				p.pos++
+				return builder.String(), nil
+			case '\n':
+				p.line++
+				builder.WriteByte(c)
+			case '\\':
+				if p.pos+1 >= len(p.src) {
+					break
+				}
+				p.pos++
+				switch e := p.src[p.pos]; e {
+				case 'n':
+					builder.WriteByte('\n')
+				case 't':
+					builder.WriteByte('\t')
+				case 'r':
+					builder.WriteByte('\r')
+				case '"', '\\', '$':
+					builder.WriteByte(e)
+				default:
+					return "", p.fail("invalid escape \\" + string(e))
+				}
+			case '$':
+				if val, err := p.expand(); err != nil {
+					return "", err
+				} else {
+					builder.WriteString(val)
+				}
+			default:
+				builder.WriteByte(c)
+			}
+		}
+		p.line = startLine
+		return "", p.fail("value is not closed with \"")
+
+	default:
+		var builder strings.Builder
+		for ; p.pos < len(p.src); p.pos++ {
+			c := p.src[p.pos]
+			if c == '\n' || (c == '#' && p.pos > 0 && (p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t')) {
+				break
+			} else if c == '$' {
+				if val, err := p.expand(); err != nil {
+					return "", err
+				} else {
+					builder.WriteString(val)
+				}
+			} else {
+				builder.WriteByte(c)
+			}
+		}
+		return strings.TrimRight(builder.String(), " \t\r"), nil
+	}
+}
+
+// Expand the variable at '$', and leave the position at its last character.
+func (p *parser) expand() (string, error) {
+
+	if p.pos+1 < len(p.src) && p.src[p.pos+1] == '{' {
+		end := strings.IndexByte(p.src[p.pos:], '}')
+		if end < 0 {
+			return "", p.fail("variable is not closed with }")
+		}
+		expr := p.src[p.pos+2 : p.pos+end]
+		p.pos += end
+
+		name, def, hasDef := expr, "", false
+		if at := strings.Index(expr, ":-"); at >= 0 {
+			name, def, hasDef = expr[:at], expr[at+2:], true
+		}
+		if val, exist := p.variable(name); exist && (len(val) > 0 || !hasDef) {
+			return val, nil
+		}
+		return def, nil
+	}
+
+	p.pos++
+	start := p.pos
+	for p.pos < len(p.src) {
+		if c := p.src[p.pos]; c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') {
+			p.pos++
+		} else {
+			break
+		}
+	}
+	name := p.src[start:p.pos]
+	if len(name) == 0 {
+		p.pos--
+		return "$", nil
+	}
+	p.pos--
+	val, _ := p.variable(name)
+	return val, nil
+}
+
+func (p *parser) variable(name string) (string, bool) {
+	if val, exist := p.vars[name]; exist {
+		return val, true
+	} else if p.lookup != nil {
+		return p.lookup(name)
+	}
+	return "", false
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+func (p *parser) skipLine() {
+	for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+		p.pos++
+	}
+}
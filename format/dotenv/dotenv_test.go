@@ -0,0 +1,81 @@
+// format/dotenv/dotenv_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dotenv_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject/format/dotenv"
+)
+
+func TestParse(t *testing.T) {
+
+	input := `# comment
+export HOST=localhost
+PORT = 5432 # inline comment
+USER='admin $HOST'
+URL="postgres://${USER:-nobody}@${HOST}:$PORT/db"
+MESSAGE="first line
+second\tline \"quoted\""
+EMPTY=
+HOME_DIR=${HOME}/app
+FALLBACK=${MISSING:-default}
+`
+	lookup := func(name string) (string, bool) {
+		if name == "HOME" {
+			return "/home/user", true
+		}
+		return "", false
+	}
+
+	vars, err := dotenv.ParseLookup(strings.NewReader(input), ".env", lookup)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := map[string]interface{}{
+		"HOST":     "localhost",
+		"PORT":     "5432",
+		"USER":     "admin $HOST",
+		"URL":      "postgres://admin $HOST@localhost:5432/db",
+		"MESSAGE":  "first line\nsecond\tline \"quoted\"",
+		"EMPTY":    "",
+		"HOME_DIR": "/home/user/app",
+		"FALLBACK": "default",
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("want %v, has %v", want, vars)
+	}
+}
+
+func TestParseError(t *testing.T) {
+
+	for input, want := range map[string]string{
+		"A=1\nB=\"open\n\n":   ".env:2: value is not closed with \"",
+		"A=1\n\n=value\n":     ".env:3: want variable name, has =value",
+		"A=1\nB 2\n":          ".env:2: want '=' after B",
+		"A='x' trailing\n":    ".env:1: unexpected characters after value of A",
+		"A=${UNCLOSED\nB=1\n": ".env:1: variable is not closed with }",
+	} {
+		if _, err := dotenv.ParseLookup(strings.NewReader(input), ".env", nil); err == nil {
+			t.Errorf("%q: want error", input)
+		} else if err.Error() != want {
+			t.Errorf("%q: want %q, has %q", input, want, err.Error())
+		}
+	}
+}
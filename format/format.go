@@ -0,0 +1,55 @@
+// format/format.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package format defines common types of format parsers, which produce maps convertible by convertobject.
+package format
+
+import (
+	"strconv"
+)
+
+type (
+	// The error of parsing, with the position in the file.
+	// Column is 0 when the parser reports only lines.
+	ParseError struct {
+		File   string
+		Line   int
+		Column int
+		Msg    string
+	}
+
+	// The error of decoding binary formats, with the offset in the input.
+	OffsetError struct {
+		Offset int
+		Msg    string
+	}
+)
+
+func (e *ParseError) Error() string {
+
+	position := e.File
+	if len(position) == 0 {
+		position = "<input>"
+	}
+	position += ":" + strconv.Itoa(e.Line)
+	if e.Column > 0 {
+		position += ":" + strconv.Itoa(e.Column)
+	}
+	return position + ": " + e.Msg
+}
+
+func (e *OffsetError) Error() string {
+	return "offset " + strconv.Itoa(e.Offset) + ": " + e.Msg
+}
@@ -0,0 +1,207 @@
+// format/ini/ini.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ini parses INI files into maps convertible by convertobject.
+//
+// Sections are nested maps, and dotted section names as [server.tls] are nested in their parents.
+// Keys are separated from values with '=' or ':', and keys ending with "[]" append values to a slice.
+// Lines starting with ';' or '#' are comments, and so are ones following whitespace in unquoted values.
+// Values quoted with double quotes accept escapes \", \\, \n, \t and \r, and ones quoted with single quotes are literal.
+package ini
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/streamwest-1629/convertobject"
+	"github.com/streamwest-1629/convertobject/format"
+)
+
+// Parse INI from the reader, filename is used in errors.
+func Parse(r io.Reader, filename string) (map[string]interface{}, error) {
+	result, _, err := ParsePositions(r, filename)
+	return result, err
+}
+
+// Parse INI from the reader, and returns positions of sections, keys and list elements with the result.
+func ParsePositions(r io.Reader, filename string) (map[string]interface{}, convertobject.Positions, error) {
+
+	result, positions := make(map[string]interface{}), make(convertobject.Positions)
+	section, sectionPath := result, ""
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+
+		line++
+		position := convertobject.Position{File: filename, Line: line}
+
+		text := strings.TrimSpace(scanner.Text())
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		fail := func(msg string) error {
+			return &format.ParseError{File: filename, Line: line, Msg: msg}
+		}
+
+		switch {
+		case len(text) == 0 || text[0] == ';' || text[0] == '#':
+			continue
+
+		case text[0] == '[':
+			end := strings.Index(text, "]")
+			if end < 0 {
+				return nil, nil, fail("section is not closed with ']'")
+			} else if rest := strings.TrimSpace(text[end+1:]); len(rest) > 0 && rest[0] != ';' && rest[0] != '#' {
+				return nil, nil, fail("unexpected characters after section: " + rest)
+			}
+
+			section, sectionPath = result, ""
+			for _, name := range strings.Split(text[1:end], ".") {
+				if name = strings.TrimSpace(name); len(name) == 0 {
+					return nil, nil, fail("section name is empty")
+				}
+				sectionPath = joinPath(sectionPath, name)
+				if child, exist := section[name]; !exist {
+					next := make(map[string]interface{})
+					section[name] = next
+					section = next
+					positions[sectionPath] = position
+				} else if next, ok := child.(map[string]interface{}); ok {
+					section = next
+				} else {
+					return nil, nil, fail("section " + name + " conflicts with the key")
+				}
+			}
+
+		default:
+			at := strings.IndexAny(text, "=:")
+			if at <= 0 {
+				return nil, nil, fail("want key = value, has " + text)
+			}
+
+			key := strings.TrimSpace(text[:at])
+			val, err := parseValue(strings.TrimSpace(text[at+1:]))
+			if err != nil {
+				return nil, nil, fail(err.Error())
+			}
+
+			if strings.HasSuffix(key, "[]") {
+				key = strings.TrimSpace(strings.TrimSuffix(key, "[]"))
+				path := joinPath(sectionPath, key)
+				if prev, exist := section[key]; !exist {
+					section[key] = []interface{}{val}
+					positions[path] = position
+					positions[path+"[0]"] = position
+				} else if list, ok := prev.([]interface{}); ok {
+					section[key] = append(list, val)
+					positions[path+"["+strconv.Itoa(len(list))+"]"] = position
+				} else {
+					return nil, nil, fail("key " + key + " is not a list")
+				}
+			} else if _, ok := section[key].(map[string]interface{}); ok {
+				return nil, nil, fail("key " + key + " conflicts with the section")
+			} else {
+				section[key] = val
+				positions[joinPath(sectionPath, key)] = position
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		// the line failed to be read follows the last line read
+		return nil, nil, &format.ParseError{File: filename, Line: line + 1, Msg: err.Error()}
+	}
+	return result, positions, nil
+}
+
+// Parse INI file of the path.
+func ParseFile(path string) (map[string]interface{}, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file, path)
+}
+
+// Parse INI from the reader, and convert it into the destination.
+// Conversion errors are prefixed with positions of the values, such as `app.ini:14`.
+func Decode(r io.Reader, filename string, dst interface{}) error {
+
+	if src, positions, err := ParsePositions(r, filename); err != nil {
+		return err
+	} else {
+		return convertobject.DirectConvert(convertobject.WithPositions(src, positions), dst)
+	}
+}
+
+func joinPath(parent, name string) string {
+	if len(parent) == 0 {
+		return name
+	}
+	return parent + "." + name
+}
+
+func parseValue(text string) (string, error) {
+
+	if len(text) == 0 {
+		return "", nil
+	}
+
+	switch quote := text[0]; quote {
+	case '"', '\'':
+		var builder strings.Builder
+		for i := 1; i < len(text); i++ {
+			c := text[i]
+			if c == quote {
+				if rest := strings.TrimSpace(text[i+1:]); len(rest) > 0 && rest[0] != ';' && rest[0] != '#' {
+					return "", errors.New("unexpected characters after quoted value: " + rest)
+				}
+				return builder.String(), nil
+			} else if c == '\\' && quote == '"' && i+1 < len(text) {
+				i++
+				switch text[i] {
+				case 'n':
+					builder.WriteByte('\n')
+				case 't':
+					builder.WriteByte('\t')
+				case 'r':
+					builder.WriteByte('\r')
+				case '"', '\\':
+					builder.WriteByte(text[i])
+				default:
+					return "", errors.New("invalid escape \\" + string(text[i]))
+				}
+			} else {
+				builder.WriteByte(c)
+			}
+		}
+		return "", errors.New("value is not closed with " + string(quote))
+
+	default:
+		for i := 1; i < len(text); i++ {
+			if (text[i] == ';' || text[i] == '#') && (text[i-1] == ' ' || text[i-1] == '\t') {
+				return strings.TrimSpace(text[:i]), nil
+			}
+		}
+		return text, nil
+	}
+}
@@ -0,0 +1,130 @@
+// format/ini/ini_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ini_test
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject/format/ini"
+)
+
+type iniServer struct {
+	Host string `map-to:"host"`
+	Port int    `map-to:"port"`
+}
+
+type iniConfig struct {
+	Name    string    `map-to:"name"`
+	Server  iniServer `map-to:"server"`
+	TLS     iniServer `map-to:"tls"`
+	Plugins []string  `map-to:"plugins"`
+	Motd    string    `map-to:"motd"`
+}
+
+func TestDecode(t *testing.T) {
+
+	input := `; global
+name = example ; comment
+plugins[] = auth
+plugins[] = "cache ; not a comment"
+
+[server]
+host: localhost
+port = 8080
+
+[server.tls]
+port = 8443
+
+[tls]
+host = 'C:\path'
+`
+	dst := iniConfig{}
+	if err := ini.Decode(strings.NewReader(input), "app.ini", &dst); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := iniConfig{
+		Name:    "example",
+		Server:  iniServer{Host: "localhost", Port: 8080},
+		TLS:     iniServer{Host: `C:\path`},
+		Plugins: []string{"auth", "cache ; not a comment"},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("want %+v, has %+v", want, dst)
+	}
+
+	src, _ := ini.Parse(strings.NewReader(input), "app.ini")
+	if tls := src["server"].(map[string]interface{})["tls"]; !reflect.DeepEqual(tls, map[string]interface{}{"port": "8443"}) {
+		t.Errorf("nested section: %v", tls)
+	}
+}
+
+func TestParseError(t *testing.T) {
+
+	for input, want := range map[string]string{
+		"[server\n":               "app.ini:1: section is not closed with ']'",
+		"a = 1\nnovalue\n":        "app.ini:2: want key = value, has novalue",
+		"a = \"open\n":            "app.ini:1: value is not closed with \"",
+		"a = 1\n[a]\n":            "app.ini:2: section a conflicts with the key",
+		"\n\nb = \"\\x\"\n":       "app.ini:3: invalid escape \\x",
+		"[s]\nk = 1\n[s.k]\n":     "app.ini:3: section k conflicts with the key",
+		"a = 1\na[] = 2\n":        "app.ini:2: key a is not a list",
+		"[s]\n[]\n":               "app.ini:2: section name is empty",
+		"a = \"x\" y\n":           "app.ini:1: unexpected characters after quoted value: y",
+		"[s] trailing\n":          "app.ini:1: unexpected characters after section: trailing",
+		"[s.t]\n[s]\nt = value\n": "app.ini:3: key t conflicts with the section",
+	} {
+		if _, err := ini.Parse(strings.NewReader(input), "app.ini"); err == nil {
+			t.Errorf("%q: want error", input)
+		} else if err.Error() != want {
+			t.Errorf("%q: want %q, has %q", input, want, err.Error())
+		}
+	}
+}
+
+func TestDecodePosition(t *testing.T) {
+
+	input := "name = example\nplugins[] = a\n\n[server]\nhost = localhost\nport = eighty\n"
+	dst := iniConfig{}
+	if err := ini.Decode(strings.NewReader(input), "app.ini", &dst); err == nil {
+		t.Error("want error")
+	} else if want := "app.ini:6: server.port"; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("want prefix %q, has %q", want, err.Error())
+	}
+
+	_, positions, err := ini.ParsePositions(strings.NewReader(input), "app.ini")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	for property, want := range map[string]int{"name": 1, "plugins[0]": 2, "server": 4, "server.port": 6} {
+		if has := positions[property]; has.Line != want || has.File != "app.ini" {
+			t.Errorf("%s: want line %d, has %+v", property, want, has)
+		}
+	}
+}
+
+func TestParseScannerError(t *testing.T) {
+
+	input := "a = 1\nb = " + strings.Repeat("x", bufio.MaxScanTokenSize) + "\n"
+	if _, err := ini.Parse(strings.NewReader(input), "app.ini"); err == nil {
+		t.Error("want error")
+	} else if want := "app.ini:2: " + bufio.ErrTooLong.Error(); err.Error() != want {
+		t.Errorf("want %q, has %q", want, err.Error())
+	}
+}
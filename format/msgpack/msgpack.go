@@ -0,0 +1,314 @@
+// format/msgpack/msgpack.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package msgpack decodes MessagePack into values convertible by convertobject.
+//
+// Maps are map[interface{}]interface{} whose integer keys are int64, so that members labeled with numbers,
+// as `map-to:"1"`, are converted from them. Integers are int64, or uint64 beyond the range of int64.
+// Floats are float64, bin are []byte, str are string and arrays are []interface{}.
+// The timestamp extension is time.Time, and other extensions are Extension.
+package msgpack
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/streamwest-1629/convertobject"
+	"github.com/streamwest-1629/convertobject/format"
+)
+
+const (
+	// Maximum depth of nested arrays and maps.
+	MaxDepth = 512
+
+	// Type of the timestamp extension.
+	TimestampType = -1
+)
+
+type (
+	// Value of the extension type other than timestamp.
+	Extension struct {
+		Type int8
+		Data []byte
+	}
+
+	decoder struct {
+		data  []byte
+		pos   int
+		depth int
+	}
+)
+
+// Decode the MessagePack object, which must be the whole of the data.
+func Unmarshal(data []byte) (interface{}, error) {
+
+	d := &decoder{data: data}
+	val, err := d.decode()
+	if err != nil {
+		return nil, err
+	} else if d.pos < len(d.data) {
+		return nil, d.fail("unexpected data after the object")
+	}
+	return val, nil
+}
+
+// Decode the MessagePack object, and convert it into the destination.
+func Decode(data []byte, dst interface{}) error {
+
+	if src, err := Unmarshal(data); err != nil {
+		return err
+	} else {
+		return convertobject.DirectConvert(src, dst)
+	}
+}
+
+func (d *decoder) fail(msg string) error {
+	return &format.OffsetError{Offset: d.pos, Msg: msg}
+}
+
+func (d *decoder) failAt(pos int, msg string) error {
+	return &format.OffsetError{Offset: pos, Msg: msg}
+}
+
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, d.fail("unexpected end of data")
+	}
+	buf := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return buf, nil
+}
+
+// Read big-endian unsigned integer of n bytes.
+func (d *decoder) uint(n int) (uint64, error) {
+	buf, err := d.read(uint64(n))
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 1:
+		return uint64(buf[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(buf)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(buf)), nil
+	default:
+		return binary.BigEndian.Uint64(buf), nil
+	}
+}
+
+func (d *decoder) decode() (interface{}, error) {
+
+	if d.depth >= MaxDepth {
+		return nil, d.fail("too deeply nested")
+	}
+	d.depth++
+	defer func() { d.depth-- }()
+
+	if d.pos >= len(d.data) {
+		return nil, d.fail("unexpected end of data")
+	}
+	start := d.pos
+	c := d.data[d.pos]
+	d.pos++
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.decodeMap(uint64(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return d.decodeArray(uint64(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return d.decodeString(start, uint64(c&0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+
+	case 0xc4, 0xc5, 0xc6:
+		if length, err := d.uint(1 << (c - 0xc4)); err != nil {
+			return nil, err
+		} else if buf, err := d.read(length); err != nil {
+			return nil, err
+		} else {
+			return append([]byte{}, buf...), nil
+		}
+
+	case 0xc7, 0xc8, 0xc9:
+		if length, err := d.uint(1 << (c - 0xc7)); err != nil {
+			return nil, err
+		} else {
+			return d.decodeExtension(start, length)
+		}
+
+	case 0xca:
+		bits, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(bits))), err
+	case 0xcb:
+		bits, err := d.uint(8)
+		return math.Float64frombits(bits), err
+
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		val, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		} else if val > math.MaxInt64 {
+			return val, nil
+		}
+		return int64(val), nil
+
+	case 0xd0:
+		val, err := d.uint(1)
+		return int64(int8(val)), err
+	case 0xd1:
+		val, err := d.uint(2)
+		return int64(int16(val)), err
+	case 0xd2:
+		val, err := d.uint(4)
+		return int64(int32(val)), err
+	case 0xd3:
+		val, err := d.uint(8)
+		return int64(val), err
+
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExtension(start, 1<<(c-0xd4))
+
+	case 0xd9, 0xda, 0xdb:
+		if length, err := d.uint(1 << (c - 0xd9)); err != nil {
+			return nil, err
+		} else {
+			return d.decodeString(start, length)
+		}
+
+	case 0xdc, 0xdd:
+		if length, err := d.uint(2 << (c - 0xdc)); err != nil {
+			return nil, err
+		} else {
+			return d.decodeArray(length)
+		}
+
+	case 0xde, 0xdf:
+		if length, err := d.uint(2 << (c - 0xde)); err != nil {
+			return nil, err
+		} else {
+			return d.decodeMap(length)
+		}
+
+	default:
+		return nil, d.failAt(start, "invalid format 0x"+strconv.FormatUint(uint64(c), 16))
+	}
+}
+
+func (d *decoder) decodeString(start int, length uint64) (string, error) {
+	buf, err := d.read(length)
+	if err != nil {
+		return "", err
+	} else if !utf8.Valid(buf) {
+		return "", d.failAt(start, "str is not valid UTF-8")
+	}
+	return string(buf), nil
+}
+
+func (d *decoder) decodeArray(length uint64) ([]interface{}, error) {
+
+	result := make([]interface{}, 0, d.capacity(length))
+	for i := uint64(0); i < length; i++ {
+		if val, err := d.decode(); err != nil {
+			return nil, err
+		} else {
+			result = append(result, val)
+		}
+	}
+	return result, nil
+}
+
+func (d *decoder) decodeMap(length uint64) (map[interface{}]interface{}, error) {
+
+	result := make(map[interface{}]interface{}, d.capacity(length)/2)
+	for i := uint64(0); i < length; i++ {
+
+		start := d.pos
+		key, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		switch k := key.(type) {
+		case int64, uint64, string, bool, float64, nil:
+		case []byte:
+			key = string(k)
+		default:
+			return nil, d.failAt(start, "map key must be integer, str, bin, bool or float")
+		}
+		if _, exist := result[key]; exist {
+			return nil, d.failAt(start, "duplicate map key")
+		}
+
+		if val, err := d.decode(); err != nil {
+			return nil, err
+		} else {
+			result[key] = val
+		}
+	}
+	return result, nil
+}
+
+// Capacity to allocate for the items, limited by the rest of the data.
+func (d *decoder) capacity(length uint64) int {
+	if rest := uint64(len(d.data) - d.pos); length > rest {
+		return int(rest)
+	}
+	return int(length)
+}
+
+func (d *decoder) decodeExtension(start int, length uint64) (interface{}, error) {
+
+	typeBuf, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.read(length)
+	if err != nil {
+		return nil, err
+	}
+
+	extType := int8(typeBuf[0])
+	if extType != TimestampType {
+		return Extension{Type: extType, Data: append([]byte{}, data...)}, nil
+	}
+
+	switch len(data) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC(), nil
+	case 8:
+		val := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(val&0x3ffffffff), int64(val>>34)).UTC(), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(data[:4])
+		return time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(nsec)).UTC(), nil
+	default:
+		return nil, d.failAt(start, "invalid length of timestamp: "+strconv.Itoa(len(data)))
+	}
+}
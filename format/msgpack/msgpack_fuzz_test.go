@@ -0,0 +1,46 @@
+// format/msgpack/msgpack_fuzz_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package msgpack_test
+
+import (
+	"testing"
+
+	"github.com/streamwest-1629/convertobject/format/msgpack"
+)
+
+func FuzzUnmarshal(f *testing.F) {
+
+	for _, seed := range []string{
+		"8301a774656d702d30310292cb4035800000000000cb401600000000000003c3",
+		"820102a1619103",
+		"d6ff514b67b0",
+		"c70205aabb",
+		"ddffffffff",
+	} {
+		f.Add(mustDecodeHex(f, seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// malformed input must be reported as errors, without panics
+		if _, err := msgpack.Unmarshal(data); err == nil {
+			dst := msgpackReading{}
+			_ = msgpack.Decode(data, &dst)
+		}
+	})
+}
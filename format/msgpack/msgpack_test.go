@@ -0,0 +1,127 @@
+// format/msgpack/msgpack_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgpack_test
+
+import (
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/streamwest-1629/convertobject/format/msgpack"
+)
+
+type msgpackReading struct {
+	Sensor string    `map-to:"1!"`
+	Values []float64 `map-to:"2"`
+	Online bool      `map-to:"3"`
+}
+
+func mustDecodeHex(t testing.TB, str string) []byte {
+	buf, err := hex.DecodeString(str)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return buf
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	cases := map[string]interface{}{
+		"00":                 int64(0),
+		"7f":                 int64(127),
+		"ff":                 int64(-1),
+		"e0":                 int64(-32),
+		"cc80":               int64(128),
+		"cd0100":             int64(256),
+		"cfffffffffffffffff": uint64(math.MaxUint64),
+		"d080":               int64(-128),
+		"d1ff00":             int64(-256),
+		"d3ffffffffffffffff": int64(-1),
+		"ca3fc00000":         1.5,
+		"cb3ff199999999999a": 1.1,
+		"c0":                 nil,
+		"c2":                 false,
+		"c3":                 true,
+		"a3616263":           "abc",
+		"d90161":             "a",
+		"c403010203":         []byte{1, 2, 3},
+		"93010203":           []interface{}{int64(1), int64(2), int64(3)},
+		"dc0002c0c3":         []interface{}{nil, true},
+		"820102a1619103":     map[interface{}]interface{}{int64(1): int64(2), "a": []interface{}{int64(3)}},
+		"d6ff514b67b0":       time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC),
+		"d40102":             msgpack.Extension{Type: 1, Data: []byte{2}},
+		"c70205aabb":         msgpack.Extension{Type: 5, Data: []byte{0xaa, 0xbb}},
+	}
+	for input, want := range cases {
+		has, err := msgpack.Unmarshal(mustDecodeHex(t, input))
+		if err != nil {
+			t.Errorf("%s: %s", input, err.Error())
+		} else if wantTime, ok := want.(time.Time); ok {
+			if hasTime, ok := has.(time.Time); !ok || !hasTime.Equal(wantTime) {
+				t.Errorf("%s: want %v, has %v", input, want, has)
+			}
+		} else if !reflect.DeepEqual(has, want) {
+			t.Errorf("%s: want %#v, has %#v", input, want, has)
+		}
+	}
+}
+
+func TestUnmarshalError(t *testing.T) {
+
+	cases := map[string]string{
+		"":             "offset 0: unexpected end of data",
+		"cd01":         "offset 1: unexpected end of data",
+		"0000":         "offset 1: unexpected data after the object",
+		"c1":           "offset 0: invalid format 0xc1",
+		"ddffffffff":   "offset 5: unexpected end of data",
+		"82010201":     "offset 3: duplicate map key",
+		"81900102":     "offset 1: map key must be integer, str, bin, bool or float",
+		"a2c328":       "offset 0: str is not valid UTF-8",
+		"c703ff010203": "offset 0: invalid length of timestamp: 3",
+	}
+	for input, want := range cases {
+		if _, err := msgpack.Unmarshal(mustDecodeHex(t, input)); err == nil {
+			t.Errorf("%s: must be error", input)
+		} else if err.Error() != want {
+			t.Errorf("%s: want %q, has %q", input, want, err.Error())
+		}
+	}
+
+	// deeply nested arrays
+	nested := make([]byte, msgpack.MaxDepth+1)
+	for i := range nested {
+		nested[i] = 0x91
+	}
+	if _, err := msgpack.Unmarshal(append(nested, 0x00)); err == nil {
+		t.Error("deeply nested arrays must be error")
+	}
+}
+
+func TestDecode(t *testing.T) {
+
+	// {1: "temp-01", 2: [21.5, 5.5], 3: true}
+	input := mustDecodeHex(t, "8301a774656d702d30310292cb4035800000000000cb401600000000000003c3")
+	dst := msgpackReading{}
+	if err := msgpack.Decode(input, &dst); err != nil {
+		t.Fatal(err.Error())
+	}
+	want := msgpackReading{Sensor: "temp-01", Values: []float64{21.5, 5.5}, Online: true}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("unexpected result: %#v", dst)
+	}
+}
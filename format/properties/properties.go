@@ -0,0 +1,197 @@
+// format/properties/properties.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package properties parses Java-style .properties files into maps convertible by convertobject.
+//
+// Each logical line is `key=value`, `key:value` or `key value`. Lines starting with '#' or '!' are comments.
+// A line ending with an odd number of backslashes continues on the next line, whose leading whitespace is skipped.
+// Keys and values accept escapes \t, \n, \r, \f, \uXXXX with surrogate pairs, and backslash followed by any other character as itself.
+// Dotted and bracket-indexed keys, such as `db.pool.max` and `hosts[0]`, are nested by convertobject.Unflatten.
+package properties
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/streamwest-1629/convertobject"
+	"github.com/streamwest-1629/convertobject/format"
+)
+
+// Parse .properties from the reader into the flat map of strings, filename is used in errors.
+func Parse(r io.Reader, filename string) (map[string]interface{}, error) {
+
+	result := make(map[string]interface{})
+	scanner := bufio.NewScanner(r)
+
+	line, startLine := 0, 0
+	logical := ""
+	continued := false
+	for scanner.Scan() {
+
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+
+		if continued {
+			logical += strings.TrimLeft(text, " \t\f")
+		} else {
+			text = strings.TrimLeft(text, " \t\f")
+			if len(text) == 0 || text[0] == '#' || text[0] == '!' {
+				continue
+			}
+			logical, startLine = text, line
+		}
+
+		if continued = endsWithContinuation(logical); continued {
+			logical = logical[:len(logical)-1]
+			continue
+		}
+
+		if key, val, err := parseLine(logical); err != nil {
+			return nil, &format.ParseError{File: filename, Line: startLine, Msg: err.Error()}
+		} else {
+			result[key] = val
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// the last line continues to the end of input
+	if continued {
+		if key, val, err := parseLine(logical); err != nil {
+			return nil, &format.ParseError{File: filename, Line: startLine, Msg: err.Error()}
+		} else {
+			result[key] = val
+		}
+	}
+	return result, nil
+}
+
+// Parse .properties file of the path into the flat map of strings.
+func ParseFile(path string) (map[string]interface{}, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file, path)
+}
+
+// Parse .properties from the reader, and convert it into the destination after unflattening keys.
+func Decode(r io.Reader, filename string, dst interface{}) error {
+
+	if flat, err := Parse(r, filename); err != nil {
+		return err
+	} else if src, err := convertobject.Unflatten(flat); err != nil {
+		return err
+	} else {
+		return convertobject.DirectConvert(src, dst)
+	}
+}
+
+func endsWithContinuation(text string) bool {
+	backslashes := 0
+	for i := len(text) - 1; i >= 0 && text[i] == '\\'; i-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+// Split the logical line into the key and the value, and unescape them.
+func parseLine(text string) (key, val string, err error) {
+
+	// the key ends at the first unescaped separator
+	end := len(text)
+	for i := 0; i < len(text); i++ {
+		if c := text[i]; c == '\\' {
+			i++
+		} else if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			end = i
+			break
+		}
+	}
+
+	rest := strings.TrimLeft(text[end:], " \t\f")
+	if len(rest) > 0 && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	if key, err = unescape(text[:end]); err != nil {
+		return "", "", err
+	} else if val, err = unescape(rest); err != nil {
+		return "", "", err
+	}
+	return key, val, nil
+}
+
+func unescape(text string) (string, error) {
+
+	if !strings.Contains(text, "\\") {
+		return text, nil
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c != '\\' || i+1 >= len(text) {
+			builder.WriteByte(c)
+			continue
+		}
+		i++
+		switch e := text[i]; e {
+		case 't':
+			builder.WriteByte('\t')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'u':
+			if i+5 > len(text) {
+				return "", errors.New("invalid escape " + text[i-1:])
+			}
+			code, err := strconv.ParseUint(text[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", errors.New("invalid escape " + text[i-1:i+5])
+			}
+			i += 4
+			// surrogate pairs, as \uD83D\uDE00, are combined into one rune
+			if r := rune(code); utf16.IsSurrogate(r) && i+6 < len(text) && text[i+1:i+3] == `\u` {
+				if low, err := strconv.ParseUint(text[i+3:i+7], 16, 16); err == nil {
+					if combined := utf16.DecodeRune(r, rune(low)); combined != unicode.ReplacementChar {
+						builder.WriteRune(combined)
+						i += 6
+						continue
+					}
+				}
+			}
+			builder.WriteRune(rune(code))
+		default:
+			builder.WriteByte(e)
+		}
+	}
+	return builder.String(), nil
+}
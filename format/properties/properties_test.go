@@ -0,0 +1,110 @@
+// format/properties/properties_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package properties_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject/format/properties"
+)
+
+type propertiesHost struct {
+	Name string `map-to:"name"`
+	Port int    `map-to:"port"`
+}
+
+type propertiesPool struct {
+	Max int `map-to:"max"`
+}
+
+type propertiesConfig struct {
+	Pool    propertiesPool   `map-to:"pool"`
+	Hosts   []propertiesHost `map-to:"hosts"`
+	Message string           `map-to:"message"`
+}
+
+func TestParse(t *testing.T) {
+
+	input := `# comment
+! also comment
+db.url = jdbc:postgresql://localhost/db
+db.user:admin
+db.password secret
+long.value = first, \
+             second
+key\ with\ spaces = value
+unicode = \u3042\t!
+empty
+`
+	flat, err := properties.Parse(strings.NewReader(input), "app.properties")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := map[string]interface{}{
+		"db.url":          "jdbc:postgresql://localhost/db",
+		"db.user":         "admin",
+		"db.password":     "secret",
+		"long.value":      "first, second",
+		"key with spaces": "value",
+		"unicode":         "\u3042\t!",
+		"empty":           "",
+	}
+	if !reflect.DeepEqual(flat, want) {
+		t.Errorf("unexpected result: %#v", flat)
+	}
+}
+
+func TestParseSurrogatePair(t *testing.T) {
+
+	flat, err := properties.Parse(strings.NewReader("emoji = \\uD83D\\uDE00!\nlone = \\uD83Dx\n"), "app.properties")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if want := map[string]interface{}{"emoji": "\U0001F600!", "lone": "\uFFFDx"}; !reflect.DeepEqual(flat, want) {
+		t.Errorf("unexpected result: %#v", flat)
+	}
+}
+
+func TestParseError(t *testing.T) {
+
+	_, err := properties.Parse(strings.NewReader("a = 1\nb = \\u30\n"), "app.properties")
+	if err == nil {
+		t.Fatal("must be error")
+	} else if !strings.HasPrefix(err.Error(), "app.properties:2: ") {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+}
+
+func TestDecode(t *testing.T) {
+
+	input := `pool.max = 8
+hosts[0].name = a
+hosts[0].port = 80
+hosts[1].name = b
+message = hello\nworld
+`
+	dst := propertiesConfig{}
+	if err := properties.Decode(strings.NewReader(input), "app.properties", &dst); err != nil {
+		t.Fatal(err.Error())
+	}
+	if dst.Pool.Max != 8 || dst.Message != "hello\nworld" ||
+		!reflect.DeepEqual(dst.Hosts, []propertiesHost{{Name: "a", Port: 80}, {Name: "b"}}) {
+		t.Errorf("unexpected result: %#v", dst)
+	}
+}
@@ -0,0 +1,488 @@
+// format/toml/toml.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package toml parses TOML v1.0 documents into maps convertible by convertobject.
+//
+// Tables are map[string]interface{}, and arrays, including arrays of tables, are []interface{}.
+// Integers are int64, floats are float64, booleans are bool, and all kinds of date-times are time.Time.
+// Local date-times, local dates and local times are in time.Local, and local times have the date 0000-01-01.
+package toml
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/streamwest-1629/convertobject"
+	"github.com/streamwest-1629/convertobject/format"
+)
+
+type (
+	// How the table was defined, which decides whether it can be extended later.
+	tableKind int
+
+	table struct {
+		kind   tableKind
+		path   string
+		values map[string]interface{}
+	}
+
+	tableArray struct {
+		tables []*table
+	}
+
+	// Part of the dotted key with its offset in the source.
+	keyPart struct {
+		name string
+		pos  int
+	}
+
+	parser struct {
+		src        string
+		pos        int
+		filename   string
+		lineStarts []int
+		root       *table
+		current    *table
+		positions  convertobject.Positions
+	}
+)
+
+const (
+	// Super-table created by headers of its sub-tables, which can be defined later once.
+	tableImplicit tableKind = iota
+	// Table defined by [header] or [[header]].
+	tableHeader
+	// Table defined by dotted keys.
+	tableDotted
+	// Inline table, which cannot be extended.
+	tableInline
+)
+
+// Parse TOML from the reader, filename is used in errors.
+func Parse(r io.Reader, filename string) (map[string]interface{}, error) {
+	result, _, err := ParsePositions(r, filename)
+	return result, err
+}
+
+// Parse TOML from the reader, and returns positions of keys and array elements with the result.
+func ParsePositions(r io.Reader, filename string) (map[string]interface{}, convertobject.Positions, error) {
+
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	} else if !utf8.Valid(buf) {
+		return nil, nil, &format.ParseError{File: filename, Line: 1, Msg: "document is not valid UTF-8"}
+	}
+
+	root := newTable(tableHeader, "")
+	p := &parser{
+		src:       string(bytes.TrimPrefix(buf, []byte("\ufeff"))),
+		filename:  filename,
+		root:      root,
+		current:   root,
+		positions: make(convertobject.Positions),
+	}
+	p.lineStarts = []int{0}
+	for i := 0; i < len(p.src); i++ {
+		if p.src[i] == '\n' {
+			p.lineStarts = append(p.lineStarts, i+1)
+		}
+	}
+
+	if err := p.parse(); err != nil {
+		return nil, nil, err
+	}
+	return export(root).(map[string]interface{}), p.positions, nil
+}
+
+// Parse TOML file of the path.
+func ParseFile(path string) (map[string]interface{}, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file, path)
+}
+
+// Parse TOML from the reader, and convert it into the destination.
+// Conversion errors are prefixed with positions of the values, such as `config.toml:14:3`.
+func Decode(r io.Reader, filename string, dst interface{}) error {
+
+	if src, positions, err := ParsePositions(r, filename); err != nil {
+		return err
+	} else {
+		return convertobject.DirectConvert(convertobject.WithPositions(src, positions), dst)
+	}
+}
+
+func newTable(kind tableKind, path string) *table {
+	return &table{kind: kind, path: path, values: make(map[string]interface{})}
+}
+
+func joinPath(parent, name string) string {
+	if len(parent) == 0 {
+		return name
+	}
+	return parent + "." + name
+}
+
+// Replace tables under construction with maps and slices.
+func export(val interface{}) interface{} {
+	switch v := val.(type) {
+	case *table:
+		result := make(map[string]interface{}, len(v.values))
+		for key, elem := range v.values {
+			result[key] = export(elem)
+		}
+		return result
+	case *tableArray:
+		result := make([]interface{}, len(v.tables))
+		for i, elem := range v.tables {
+			result[i] = export(elem)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, elem := range v {
+			result[i] = export(elem)
+		}
+		return result
+	default:
+		return val
+	}
+}
+
+// Position of the offset in the source.
+func (p *parser) position(pos int) convertobject.Position {
+
+	if pos > len(p.src) {
+		pos = len(p.src)
+	}
+	line := sort.SearchInts(p.lineStarts, pos+1) - 1
+	return convertobject.Position{
+		File:   p.filename,
+		Line:   line + 1,
+		Column: utf8.RuneCountInString(p.src[p.lineStarts[line]:pos]) + 1,
+	}
+}
+
+// Record the position of the property, unless it is already recorded.
+func (p *parser) record(path string, pos int) {
+	if _, exist := p.positions[path]; !exist {
+		p.positions[path] = p.position(pos)
+	}
+}
+
+// Report the error at the offset in the source.
+func (p *parser) failAt(pos int, msg string) error {
+	position := p.position(pos)
+	return &format.ParseError{File: position.File, Line: position.Line, Column: position.Column, Msg: msg}
+}
+
+func (p *parser) fail(msg string) error {
+	return p.failAt(p.pos, msg)
+}
+
+func (p *parser) parse() error {
+
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.src) {
+			return nil
+		}
+
+		var err error
+		if p.src[p.pos] == '[' {
+			err = p.parseHeader()
+		} else {
+			err = p.parseKeyval(p.current)
+		}
+		if err != nil {
+			return err
+		} else if err := p.endOfLine(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) parseHeader() error {
+
+	isArray := strings.HasPrefix(p.src[p.pos:], "[[")
+	if isArray {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+
+	p.skipSpaces()
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpaces()
+
+	if isArray {
+		if !strings.HasPrefix(p.src[p.pos:], "]]") {
+			return p.fail("array of tables header is not closed with ]]")
+		}
+		p.pos += 2
+	} else {
+		if p.pos >= len(p.src) || p.src[p.pos] != ']' {
+			return p.fail("table header is not closed with ]")
+		}
+		p.pos++
+	}
+
+	// super-tables, which are created implicitly
+	parent := p.root
+	for _, key := range keys[:len(keys)-1] {
+		switch child := parent.values[key.name].(type) {
+		case nil:
+			next := newTable(tableImplicit, joinPath(parent.path, key.name))
+			parent.values[key.name] = next
+			p.record(next.path, key.pos)
+			parent = next
+		case *table:
+			if child.kind == tableInline {
+				return p.failAt(key.pos, "cannot extend inline table "+key.name)
+			}
+			parent = child
+		case *tableArray:
+			parent = child.tables[len(child.tables)-1]
+		default:
+			return p.failAt(key.pos, "key "+key.name+" is already defined as a value")
+		}
+	}
+
+	last := keys[len(keys)-1]
+	path := joinPath(parent.path, last.name)
+	if isArray {
+		p.record(path, last.pos)
+		switch child := parent.values[last.name].(type) {
+		case nil:
+			next := newTable(tableHeader, path+"[0]")
+			parent.values[last.name] = &tableArray{tables: []*table{next}}
+			p.current = next
+		case *tableArray:
+			next := newTable(tableHeader, path+"["+strconv.Itoa(len(child.tables))+"]")
+			child.tables = append(child.tables, next)
+			p.current = next
+		default:
+			return p.failAt(last.pos, "key "+last.name+" is already defined, not as array of tables")
+		}
+		p.record(p.current.path, last.pos)
+	} else {
+		switch child := parent.values[last.name].(type) {
+		case nil:
+			next := newTable(tableHeader, path)
+			parent.values[last.name] = next
+			p.record(path, last.pos)
+			p.current = next
+		case *table:
+			if child.kind != tableImplicit {
+				return p.failAt(last.pos, "table "+last.name+" is already defined")
+			}
+			child.kind = tableHeader
+			p.positions[path] = p.position(last.pos)
+			p.current = child
+		default:
+			return p.failAt(last.pos, "key "+last.name+" is already defined, not as table")
+		}
+	}
+	return nil
+}
+
+// Parse `key = value` and set it into the table.
+func (p *parser) parseKeyval(into *table) error {
+
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpaces()
+	if p.pos >= len(p.src) || p.src[p.pos] != '=' {
+		return p.fail("want = after key")
+	}
+	p.pos++
+	p.skipSpaces()
+
+	// tables created by dotted keys
+	parent := into
+	for _, key := range keys[:len(keys)-1] {
+		switch child := parent.values[key.name].(type) {
+		case nil:
+			next := newTable(tableDotted, joinPath(parent.path, key.name))
+			parent.values[key.name] = next
+			p.record(next.path, key.pos)
+			parent = next
+		case *table:
+			if child.kind != tableDotted {
+				return p.failAt(key.pos, "cannot extend table "+key.name+" with dotted keys")
+			}
+			parent = child
+		default:
+			return p.failAt(key.pos, "key "+key.name+" is already defined, not as table")
+		}
+	}
+
+	last := keys[len(keys)-1]
+	if _, exist := parent.values[last.name]; exist {
+		return p.failAt(last.pos, "key "+last.name+" is already defined")
+	}
+	path := joinPath(parent.path, last.name)
+	p.record(path, last.pos)
+	if val, err := p.parseValue(path); err != nil {
+		return err
+	} else {
+		parent.values[last.name] = val
+	}
+	return nil
+}
+
+// Parse the dotted key.
+func (p *parser) parseKey() ([]keyPart, error) {
+
+	keys := []keyPart{}
+	for {
+		start := p.pos
+		if name, err := p.parseSimpleKey(); err != nil {
+			return nil, err
+		} else {
+			keys = append(keys, keyPart{name: name, pos: start})
+		}
+
+		p.skipSpaces()
+		if p.pos < len(p.src) && p.src[p.pos] == '.' {
+			p.pos++
+			p.skipSpaces()
+		} else {
+			return keys, nil
+		}
+	}
+}
+
+func (p *parser) parseSimpleKey() (string, error) {
+
+	if p.pos >= len(p.src) {
+		return "", p.fail("want key, has end of document")
+	}
+
+	switch p.src[p.pos] {
+	case '"':
+		if strings.HasPrefix(p.src[p.pos:], `"""`) {
+			return "", p.fail("multi-line string cannot be used as key")
+		}
+		return p.parseBasicString()
+	case '\'':
+		if strings.HasPrefix(p.src[p.pos:], `'''`) {
+			return "", p.fail("multi-line string cannot be used as key")
+		}
+		return p.parseLiteralString()
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) && isBareKeyChar(p.src[p.pos]) {
+		p.pos++
+	}
+	if start == p.pos {
+		return "", p.fail("want key, has " + p.peekText())
+	}
+	return p.src[start:p.pos], nil
+}
+
+func isBareKeyChar(c byte) bool {
+	return c == '_' || c == '-' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// Skip whitespaces, newlines and comments.
+func (p *parser) skipBlank() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\n':
+			p.pos++
+		case '\r':
+			if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+				p.pos += 2
+			} else {
+				return
+			}
+		case '#':
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) skipComment() {
+	for p.pos < len(p.src) && p.src[p.pos] != '\n' && !strings.HasPrefix(p.src[p.pos:], "\r\n") {
+		p.pos++
+	}
+}
+
+// Rest of the line must be empty or a comment.
+func (p *parser) endOfLine() error {
+
+	p.skipSpaces()
+	if p.pos < len(p.src) && p.src[p.pos] == '#' {
+		if err := p.checkComment(); err != nil {
+			return err
+		}
+		p.skipComment()
+	}
+	if p.pos >= len(p.src) {
+		return nil
+	} else if p.src[p.pos] == '\n' {
+		p.pos++
+		return nil
+	} else if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+		p.pos += 2
+		return nil
+	}
+	return p.fail("unexpected characters at the end of line: " + p.peekText())
+}
+
+// Comments cannot contain control characters other than tab.
+func (p *parser) checkComment() error {
+	for i := p.pos; i < len(p.src) && p.src[i] != '\n'; i++ {
+		if c := p.src[i]; (c < 0x20 && c != '\t' && !(c == '\r' && i+1 < len(p.src) && p.src[i+1] == '\n')) || c == 0x7f {
+			return p.failAt(i, "control character in comment")
+		}
+	}
+	return nil
+}
+
+// Text from the current position to the end of line, used in errors.
+func (p *parser) peekText() string {
+	if p.pos >= len(p.src) {
+		return "end of document"
+	}
+	text := strings.SplitN(p.src[p.pos:], "\n", 2)[0]
+	return strings.TrimRight(text, "\r")
+}
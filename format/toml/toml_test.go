@@ -0,0 +1,256 @@
+// format/toml/toml_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package toml_test
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/streamwest-1629/convertobject/format/toml"
+)
+
+type tomlChild struct {
+	Name string    `map-to:"name"`
+	Date time.Time `map-to:"date"`
+}
+
+type tomlOwner struct {
+	Name string `map-to:"name"`
+	Age  int    `map-to:"age"`
+}
+
+type tomlConfig struct {
+	Title    string      `map-to:"title"`
+	Owner    tomlOwner   `map-to:"owner"`
+	Ports    []int       `map-to:"ports"`
+	Children []tomlChild `map-to:"children"`
+}
+
+func TestParse(t *testing.T) {
+
+	input := `# This is a TOML document
+title = "TOML \"Example\"\u00e9"
+literal = 'C:\Users\nodejs'
+multi = """
+Roses are red \
+    Violets are blue"""
+multiLiteral = '''
+first
+second'''
+quotes = """a""b"""""
+int = +1_000
+hex = 0xDEAD_beef
+oct = 0o755
+bin = 0b1101
+float = -3.14e-2
+inf = -inf
+bool = true
+"quoted key" = 1
+site."google.com" = true
+odt = 1979-05-27T07:32:00.999999Z
+spaced = 1979-05-27 07:32:00+09:00
+array = [ 1, 2,
+  # comment in array
+  3, ]
+nested = [[1, 2], ["a", 'b']]
+inline = { x = 1, y.z = "deep" }
+
+[owner]
+name = "Tom"
+
+[a.b.c]
+d = 1
+
+[a]
+e = 2
+
+[[products]]
+name = "Hammer"
+
+[[products]]
+
+[[products]]
+name = "Nail"
+[products.detail]
+size = 3
+`
+	src, err := toml.Parse(strings.NewReader(input), "config.toml")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := map[string]interface{}{
+		"title":        "TOML \"Example\"\u00e9",
+		"literal":      `C:\Users\nodejs`,
+		"multi":        "Roses are red Violets are blue",
+		"multiLiteral": "first\nsecond",
+		"quotes":       `a""b""`,
+		"int":          int64(1000),
+		"hex":          int64(0xdeadbeef),
+		"oct":          int64(0755),
+		"bin":          int64(13),
+		"float":        -0.0314,
+		"inf":          math.Inf(-1),
+		"bool":         true,
+		"quoted key":   int64(1),
+		"site":         map[string]interface{}{"google.com": true},
+		"odt":          time.Date(1979, 5, 27, 7, 32, 0, 999999000, time.UTC),
+		"spaced":       time.Date(1979, 5, 27, 7, 32, 0, 0, time.FixedZone("", 9*60*60)),
+		"array":        []interface{}{int64(1), int64(2), int64(3)},
+		"nested":       []interface{}{[]interface{}{int64(1), int64(2)}, []interface{}{"a", "b"}},
+		"inline":       map[string]interface{}{"x": int64(1), "y": map[string]interface{}{"z": "deep"}},
+		"owner":        map[string]interface{}{"name": "Tom"},
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{"c": map[string]interface{}{"d": int64(1)}},
+			"e": int64(2),
+		},
+		"products": []interface{}{
+			map[string]interface{}{"name": "Hammer"},
+			map[string]interface{}{},
+			map[string]interface{}{"name": "Nail", "detail": map[string]interface{}{"size": int64(3)}},
+		},
+	}
+
+	for key, val := range want {
+		if has := src[key]; key == "odt" || key == "spaced" {
+			if tm, ok := has.(time.Time); !ok || !tm.Equal(val.(time.Time)) {
+				t.Errorf("%s: want %v, has %v", key, val, has)
+			}
+		} else if !reflect.DeepEqual(has, val) {
+			t.Errorf("%s: want %#v, has %#v", key, val, has)
+		}
+	}
+	if len(src) != len(want) {
+		t.Errorf("unexpected keys: %v", src)
+	}
+}
+
+func TestParseLocalTime(t *testing.T) {
+
+	src, err := toml.Parse(strings.NewReader("ldt = 1979-05-27T07:32:00\nld = 1979-05-27\nlt = 07:32:00.5\n"), "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if has := src["ldt"].(time.Time); !has.Equal(time.Date(1979, 5, 27, 7, 32, 0, 0, time.Local)) {
+		t.Errorf("ldt: %v", has)
+	}
+	if has := src["ld"].(time.Time); !has.Equal(time.Date(1979, 5, 27, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("ld: %v", has)
+	}
+	if has := src["lt"].(time.Time); has.Hour() != 7 || has.Minute() != 32 || has.Nanosecond() != 500000000 {
+		t.Errorf("lt: %v", has)
+	}
+}
+
+func TestParseError(t *testing.T) {
+
+	cases := map[string]string{
+		"a = 1\na = 2":         "config.toml:2:1: key a is already defined",
+		"[x]\n[x]":             "config.toml:2:2: table x is already defined",
+		"a = {b = 1}\n[a]":     "config.toml:2:2: table a is already defined",
+		"a = {b = 1}\na.c = 2": "config.toml:2:1: cannot extend table a with dotted keys",
+		"[fruit]\napple.color = 1\n[fruit.apple]": "config.toml:3:8: table apple is already defined",
+		"a = [1]\n[[a]]":         "config.toml:2:3: key a is already defined, not as array of tables",
+		"a = 01":                 "config.toml:1:5: invalid value: 01",
+		"a = 1__0":               "config.toml:1:5: invalid value: 1__0",
+		"a = \"abc":              "config.toml:1:5: string is not closed with \"",
+		"a = 1 b = 2":            "config.toml:1:7: unexpected characters at the end of line: b = 2",
+		"a = \"\\q\"":            "config.toml:1:6: invalid escape: \\q",
+		"  key":                  "config.toml:1:6: want = after key",
+		"a = 1979-13-27":         "config.toml:1:5: invalid date-time: 1979-13-27",
+		"a = { b = 1,\n c = 2 }": "config.toml:1:13: want key, has ",
+	}
+
+	for input, want := range cases {
+		if _, err := toml.Parse(strings.NewReader(input), "config.toml"); err == nil {
+			t.Errorf("%q: must be error", input)
+		} else if err.Error() != want {
+			t.Errorf("%q: want %q, has %q", input, want, err.Error())
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+
+	input := `title = "family"
+ports = [8000, 8001]
+
+[owner]
+name = "Tom"
+age = 42
+
+[[children]]
+name = "Alice"
+date = 2010-04-01
+
+[[children]]
+name = "Bob"
+date = 2012-08-15T10:00:00Z
+`
+	dst := tomlConfig{}
+	if err := toml.Decode(strings.NewReader(input), "config.toml", &dst); err != nil {
+		t.Fatal(err.Error())
+	}
+	if dst.Title != "family" || dst.Owner != (tomlOwner{Name: "Tom", Age: 42}) ||
+		!reflect.DeepEqual(dst.Ports, []int{8000, 8001}) {
+		t.Errorf("unexpected result: %#v", dst)
+	}
+	if len(dst.Children) != 2 || dst.Children[0].Name != "Alice" ||
+		!dst.Children[1].Date.Equal(time.Date(2012, 8, 15, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected children: %#v", dst.Children)
+	}
+}
+
+func TestDecodePosition(t *testing.T) {
+
+	input := `title = "family"
+
+[[children]]
+name = "Alice"
+date = 2010-04-01
+
+[[children]]
+name = "Bob"
+  date = 12
+`
+	dst := tomlConfig{}
+	err := toml.Decode(strings.NewReader(input), "config.toml", &dst)
+	if err == nil {
+		t.Fatal("must be error")
+	} else if want := "config.toml:9:3: children[1].date is invalid type"; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("want %q, has %q", want, err.Error())
+	}
+
+	_, positions, err := toml.ParsePositions(strings.NewReader(input+"ports = [\n  1,\n  2]\n"), "config.toml")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	for property, want := range map[string]string{
+		"title":                "config.toml:1:1",
+		"children":             "config.toml:3:3",
+		"children[1]":          "config.toml:7:3",
+		"children[1].name":     "config.toml:8:1",
+		"children[1].ports":    "config.toml:10:1",
+		"children[1].ports[1]": "config.toml:12:3",
+	} {
+		if has, exist := positions[property]; !exist || has.String() != want {
+			t.Errorf("%s: want %s, has %v", property, want, has)
+		}
+	}
+}
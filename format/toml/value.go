@@ -0,0 +1,412 @@
+// format/toml/value.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package toml
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/streamwest-1629/convertobject/standard"
+)
+
+var (
+	decimalMatches  = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)$`)
+	hexMatches      = regexp.MustCompile(`^0x[0-9a-fA-F](_?[0-9a-fA-F])*$`)
+	octMatches      = regexp.MustCompile(`^0o[0-7](_?[0-7])*$`)
+	binMatches      = regexp.MustCompile(`^0b[01](_?[01])*$`)
+	floatMatches    = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][+-]?[0-9](_?[0-9])*)?$`)
+	dateTimeMatches = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}([Tt ][0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?([Zz]|[+-][0-9]{2}:[0-9]{2})?)?$`)
+	timeMatches     = regexp.MustCompile(`^[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?$`)
+	dateMatches     = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+)
+
+// Parse the value of the property path, positions of nested values are recorded.
+func (p *parser) parseValue(path string) (interface{}, error) {
+
+	if p.pos >= len(p.src) {
+		return nil, p.fail("want value, has end of document")
+	}
+
+	switch c := p.src[p.pos]; {
+	case c == '"':
+		if strings.HasPrefix(p.src[p.pos:], `"""`) {
+			return p.parseMultiLineString('"')
+		}
+		return p.parseBasicString()
+	case c == '\'':
+		if strings.HasPrefix(p.src[p.pos:], `'''`) {
+			return p.parseMultiLineString('\'')
+		}
+		return p.parseLiteralString()
+	case c == '[':
+		return p.parseArray(path)
+	case c == '{':
+		return p.parseInlineTable(path)
+	case strings.HasPrefix(p.src[p.pos:], "true") && p.isValueEnd(p.pos+4):
+		p.pos += 4
+		return true, nil
+	case strings.HasPrefix(p.src[p.pos:], "false") && p.isValueEnd(p.pos+5):
+		p.pos += 5
+		return false, nil
+	default:
+		return p.parseScalar()
+	}
+}
+
+func (p *parser) isValueEnd(pos int) bool {
+	if pos >= len(p.src) {
+		return true
+	}
+	switch p.src[pos] {
+	case ' ', '\t', '\r', '\n', ',', ']', '}', '#':
+		return true
+	default:
+		return false
+	}
+}
+
+// Parse numbers and date-times.
+func (p *parser) parseScalar() (interface{}, error) {
+
+	start := p.pos
+	for !p.isValueEnd(p.pos) {
+		p.pos++
+	}
+	// date and time may be separated by a space
+	if dateMatches.MatchString(p.src[start:p.pos]) && p.pos+3 < len(p.src) && p.src[p.pos] == ' ' &&
+		isDigit(p.src[p.pos+1]) && isDigit(p.src[p.pos+2]) && p.src[p.pos+3] == ':' {
+		for p.pos++; !p.isValueEnd(p.pos); p.pos++ {
+		}
+	}
+
+	token := p.src[start:p.pos]
+	if len(token) == 0 {
+		return nil, p.failAt(start, "want value, has "+p.peekText())
+	}
+	invalid := func(kind string) error {
+		return p.failAt(start, "invalid "+kind+": "+token)
+	}
+
+	switch {
+	case dateTimeMatches.MatchString(token) || timeMatches.MatchString(token):
+		if val, err := parseDateTime(token); err != nil {
+			return nil, invalid("date-time")
+		} else {
+			return val, nil
+		}
+
+	case len(token) <= 4 && (strings.HasSuffix(token, "inf") || strings.HasSuffix(token, "nan")):
+		switch token {
+		case "inf", "+inf":
+			return math.Inf(1), nil
+		case "-inf":
+			return math.Inf(-1), nil
+		case "nan", "+nan", "-nan":
+			return math.NaN(), nil
+		}
+		return nil, invalid("float")
+
+	case strings.HasPrefix(token, "0x") || strings.HasPrefix(token, "0o") || strings.HasPrefix(token, "0b"):
+		matches, base := hexMatches, 16
+		if token[1] == 'o' {
+			matches, base = octMatches, 8
+		} else if token[1] == 'b' {
+			matches, base = binMatches, 2
+		}
+		if !matches.MatchString(token) {
+			return nil, invalid("integer")
+		}
+		if val, err := strconv.ParseUint(strings.ReplaceAll(token[2:], "_", ""), base, 64); err != nil || val > math.MaxInt64 {
+			return nil, invalid("integer")
+		} else {
+			return int64(val), nil
+		}
+
+	case decimalMatches.MatchString(token):
+		if val, err := strconv.ParseInt(strings.ReplaceAll(token, "_", ""), 10, 64); err != nil {
+			return nil, invalid("integer")
+		} else {
+			return val, nil
+		}
+
+	case floatMatches.MatchString(token) && strings.ContainsAny(token, ".eE"):
+		if val, err := strconv.ParseFloat(strings.ReplaceAll(token, "_", ""), 64); err != nil {
+			return nil, invalid("float")
+		} else {
+			return val, nil
+		}
+
+	default:
+		return nil, p.failAt(start, "invalid value: "+token)
+	}
+}
+
+func parseDateTime(token string) (time.Time, error) {
+
+	// fractions beyond nanoseconds are truncated
+	if at := strings.IndexByte(token, '.'); at >= 0 {
+		end := at + 1
+		for end < len(token) && isDigit(token[end]) {
+			end++
+		}
+		if end-at-1 > 9 {
+			token = token[:at+10] + token[end:]
+		}
+	}
+	return standard.ParseTime(token)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func (p *parser) parseArray(path string) ([]interface{}, error) {
+
+	start := p.pos
+	p.pos++
+	result := []interface{}{}
+	for {
+		if err := p.skipArrayBlank(); err != nil {
+			return nil, err
+		} else if p.pos >= len(p.src) {
+			return nil, p.failAt(start, "array is not closed with ]")
+		} else if p.src[p.pos] == ']' {
+			p.pos++
+			return result, nil
+		}
+
+		elemPath := path + "[" + strconv.Itoa(len(result)) + "]"
+		p.record(elemPath, p.pos)
+		if val, err := p.parseValue(elemPath); err != nil {
+			return nil, err
+		} else {
+			result = append(result, val)
+		}
+
+		if err := p.skipArrayBlank(); err != nil {
+			return nil, err
+		} else if p.pos >= len(p.src) {
+			return nil, p.failAt(start, "array is not closed with ]")
+		} else if p.src[p.pos] == ',' {
+			p.pos++
+		} else if p.src[p.pos] != ']' {
+			return nil, p.fail("want , or ] in array, has " + p.peekText())
+		}
+	}
+}
+
+// Skip whitespaces, newlines and comments in arrays.
+func (p *parser) skipArrayBlank() error {
+	p.skipBlank()
+	if p.pos < len(p.src) && p.src[p.pos] == '\r' {
+		return p.fail("carriage return without line feed")
+	}
+	return nil
+}
+
+func (p *parser) parseInlineTable(path string) (*table, error) {
+
+	start := p.pos
+	p.pos++
+	result := newTable(tableInline, path)
+
+	p.skipSpaces()
+	if p.pos < len(p.src) && p.src[p.pos] == '}' {
+		p.pos++
+		return result, nil
+	}
+
+	for {
+		p.skipSpaces()
+		if err := p.parseKeyval(result); err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.pos >= len(p.src) || p.src[p.pos] == '\n' || p.src[p.pos] == '\r' {
+			return nil, p.failAt(start, "inline table is not closed with } in the line")
+		} else if p.src[p.pos] == '}' {
+			p.pos++
+			freeze(result)
+			return result, nil
+		} else if p.src[p.pos] != ',' {
+			return nil, p.fail("want , or } in inline table, has " + p.peekText())
+		}
+		p.pos++
+	}
+}
+
+// Mark tables defined by dotted keys in the inline table as inline, not to be extended.
+func freeze(t *table) {
+	t.kind = tableInline
+	for _, val := range t.values {
+		if child, ok := val.(*table); ok {
+			freeze(child)
+		}
+	}
+}
+
+func (p *parser) parseBasicString() (string, error) {
+
+	start := p.pos
+	p.pos++
+	var builder strings.Builder
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == '"':
+			p.pos++
+			return builder.String(), nil
+		case c == '\\':
+			if err := p.parseEscape(&builder); err != nil {
+				return "", err
+			}
+		case c == '\n' || c == '\r':
+			return "", p.failAt(start, "string is not closed with \" in the line")
+		case isControl(c):
+			return "", p.fail("control character in string")
+		default:
+			builder.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.failAt(start, "string is not closed with \"")
+}
+
+func (p *parser) parseLiteralString() (string, error) {
+
+	start := p.pos
+	p.pos++
+	for i := p.pos; i < len(p.src); i++ {
+		switch c := p.src[i]; {
+		case c == '\'':
+			p.pos = i + 1
+			return p.src[start+1 : i], nil
+		case c == '\n' || c == '\r':
+			return "", p.failAt(start, "string is not closed with ' in the line")
+		case isControl(c):
+			return "", p.failAt(i, "control character in string")
+		}
+	}
+	return "", p.failAt(start, "string is not closed with '")
+}
+
+// Parse multi-line basic or literal string, quoted with three quotes.
+func (p *parser) parseMultiLineString(quote byte) (string, error) {
+
+	start := p.pos
+	p.pos += 3
+	// a newline immediately following the opening delimiter is trimmed
+	if strings.HasPrefix(p.src[p.pos:], "\n") {
+		p.pos++
+	} else if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+		p.pos += 2
+	}
+
+	var builder strings.Builder
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == quote:
+			quotes := 0
+			for p.pos+quotes < len(p.src) && p.src[p.pos+quotes] == quote {
+				quotes++
+			}
+			if quotes < 3 {
+				builder.WriteString(p.src[p.pos : p.pos+quotes])
+				p.pos += quotes
+				continue
+			} else if quotes > 5 {
+				return "", p.fail("too many quotes in multi-line string")
+			}
+			// up to two quotes are allowed just before the closing delimiter
+			builder.WriteString(p.src[p.pos : p.pos+quotes-3])
+			p.pos += quotes
+			return builder.String(), nil
+
+		case c == '\\' && quote == '"':
+			// line ending backslash trims whitespaces and newlines
+			rest := strings.TrimLeft(p.src[p.pos+1:], " \t")
+			if strings.HasPrefix(rest, "\n") || strings.HasPrefix(rest, "\r\n") {
+				rest = strings.TrimLeft(rest, " \t\r\n")
+				p.pos = len(p.src) - len(rest)
+			} else if err := p.parseEscape(&builder); err != nil {
+				return "", err
+			}
+
+		case c == '\r':
+			if !strings.HasPrefix(p.src[p.pos:], "\r\n") {
+				return "", p.fail("carriage return without line feed")
+			}
+			builder.WriteString("\r\n")
+			p.pos += 2
+
+		case c != '\n' && isControl(c):
+			return "", p.fail("control character in string")
+
+		default:
+			builder.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.failAt(start, "multi-line string is not closed")
+}
+
+// Parse the escape sequence at the backslash.
+func (p *parser) parseEscape(builder *strings.Builder) error {
+
+	start := p.pos
+	if p.pos+1 >= len(p.src) {
+		return p.fail("invalid escape at end of document")
+	}
+	p.pos += 2
+	switch e := p.src[p.pos-1]; e {
+	case 'b':
+		builder.WriteByte('\b')
+	case 't':
+		builder.WriteByte('\t')
+	case 'n':
+		builder.WriteByte('\n')
+	case 'f':
+		builder.WriteByte('\f')
+	case 'r':
+		builder.WriteByte('\r')
+	case '"', '\\':
+		builder.WriteByte(e)
+	case 'u', 'U':
+		digits := 4
+		if e == 'U' {
+			digits = 8
+		}
+		if p.pos+digits > len(p.src) {
+			return p.failAt(start, "invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+digits], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.failAt(start, "invalid unicode escape: "+p.src[start:p.pos+digits])
+		}
+		builder.WriteRune(rune(code))
+		p.pos += digits
+	default:
+		return p.failAt(start, "invalid escape: \\"+string(e))
+	}
+	return nil
+}
+
+func isControl(c byte) bool {
+	return (c < 0x20 && c != '\t') || c == 0x7f
+}
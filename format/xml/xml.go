@@ -0,0 +1,226 @@
+// format/xml/xml.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package xml parses XML documents into maps convertible by convertobject, so that map-to labels apply to XML.
+//
+// The root element is the result map. Attributes and child elements are keys of their local names,
+// and repeated child elements are []interface{}. Elements without attributes and child elements are strings of their text,
+// and text of other elements is under Decoder.TextKey. Leading and trailing whitespaces of text are trimmed.
+//
+// With Decoder.Struct, child elements of slice members are []interface{} even when they appear once.
+// Elements of slice members whose items are not structures may wrap items, as <tags><tag>a</tag><tag>b</tag></tags>,
+// when they have only the child elements, without attributes and text.
+package xml
+
+import (
+	stdxml "encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/streamwest-1629/convertobject"
+)
+
+const (
+	// Default key of text content.
+	DefaultTextKey = "#text"
+)
+
+type (
+	// Decoder of XML with its options.
+	Decoder struct {
+		// Key of text content of elements with attributes or child elements.
+		TextKey string
+		// Prefix of attribute keys, empty to share keys with child elements.
+		AttrPrefix string
+		// Structure to decide which child elements are lists, or nil.
+		Struct *convertobject.Struct
+	}
+)
+
+// Create decoder with default options.
+func NewDecoder() *Decoder {
+	return &Decoder{TextKey: DefaultTextKey}
+}
+
+// Parse XML from the reader with default options.
+func Parse(r io.Reader) (map[string]interface{}, error) {
+	return NewDecoder().Parse(r)
+}
+
+// Parse XML from the reader, and convert it into the destination.
+// When the destination is a structure, it decides which child elements are lists.
+func Decode(r io.Reader, dst interface{}) error {
+
+	d := NewDecoder()
+	if __type := reflect.TypeOf(dst); __type.Kind() == reflect.Ptr && __type.Elem().Kind() == reflect.Struct {
+		compiled, err := convertobject.CompileStruct(dst)
+		if err != nil {
+			return err
+		}
+		d.Struct = compiled
+	}
+	return d.Decode(r, dst)
+}
+
+// Parse XML from the reader, and returns the map of the root element.
+func (d *Decoder) Parse(r io.Reader) (map[string]interface{}, error) {
+
+	dec := stdxml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil, errors.New("XML has no root element")
+		} else if err != nil {
+			return nil, err
+		}
+
+		if start, ok := tok.(stdxml.StartElement); ok {
+			val, _, err := d.element(dec, start, d.Struct)
+			if err != nil {
+				return nil, err
+			}
+			if content, ok := val.(map[string]interface{}); ok {
+				return content, nil
+			} else if text := val.(string); len(text) > 0 {
+				return map[string]interface{}{d.TextKey: text}, nil
+			}
+			return map[string]interface{}{}, nil
+		}
+	}
+}
+
+// Parse XML from the reader, and convert it into the destination.
+func (d *Decoder) Decode(r io.Reader, dst interface{}) error {
+
+	if src, err := d.Parse(r); err != nil {
+		return err
+	} else {
+		return convertobject.DirectConvert(src, dst)
+	}
+}
+
+// Build the value of the element, guided by the converter of the member, which may be nil.
+// Returns whether the element has attributes, as well as the value.
+func (d *Decoder) element(dec *stdxml.Decoder, start stdxml.StartElement, guide convertobject.Convert) (val interface{}, hasAttr bool, err error) {
+
+	guideStruct := structOf(guide)
+	content := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		content[d.AttrPrefix+attr.Name.Local] = attr.Value
+		hasAttr = true
+	}
+
+	var text strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, false, err
+		}
+
+		switch t := tok.(type) {
+		case stdxml.StartElement:
+			name := t.Name.Local
+			childGuide, isList := memberGuide(guideStruct, name)
+			val, childHasAttr, err := d.element(dec, t, childGuide)
+			if err != nil {
+				return nil, false, err
+			}
+
+			// items wrapped by the element of the slice member, which has only the child element
+			if wrapped, ok := val.(map[string]interface{}); ok && isList && !childHasAttr && len(wrapped) == 1 && structOf(childGuide) == nil {
+				for _, items := range wrapped {
+					if list, ok := items.([]interface{}); ok {
+						content[name] = append(listOf(content[name]), list...)
+					} else {
+						content[name] = append(listOf(content[name]), items)
+					}
+				}
+				continue
+			}
+
+			if prev, exist := content[name]; exist {
+				content[name] = append(listOf(prev), val)
+			} else if isList {
+				content[name] = []interface{}{val}
+			} else {
+				content[name] = val
+			}
+
+		case stdxml.CharData:
+			text.Write(t)
+
+		case stdxml.EndElement:
+			trimmed := strings.TrimSpace(text.String())
+			if len(content) == 0 {
+				return trimmed, hasAttr, nil
+			} else if len(trimmed) > 0 {
+				content[d.TextKey] = trimmed
+			}
+			return content, hasAttr, nil
+		}
+	}
+}
+
+func listOf(val interface{}) []interface{} {
+	if val == nil {
+		return []interface{}{}
+	} else if list, ok := val.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{val}
+}
+
+// Find the member of the keyname, including members of embedded structures,
+// and returns the converter of its items and whether it is a slice.
+func memberGuide(s *convertobject.Struct, keyname string) (guide convertobject.Convert, isList bool) {
+
+	if s == nil {
+		return nil, false
+	}
+	for i := range s.Members {
+		member := &s.Members[i]
+		if member.Embed {
+			if guide, isList := memberGuide(structOf(member.Convert), keyname); guide != nil {
+				return guide, isList
+			}
+		} else if member.Keyname == keyname {
+			convert := member.Convert
+			if ptr, ok := convert.(*convertobject.Ptr); ok {
+				convert = ptr.Internal
+			}
+			if slice, ok := convert.(*convertobject.Slice); ok {
+				return slice.Internal, true
+			}
+			return convert, false
+		}
+	}
+	return nil, false
+}
+
+func structOf(convert convertobject.Convert) *convertobject.Struct {
+	switch c := convert.(type) {
+	case *convertobject.Struct:
+		return c
+	case *convertobject.Ptr:
+		return structOf(c.Internal)
+	default:
+		return nil
+	}
+}
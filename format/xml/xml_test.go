@@ -0,0 +1,153 @@
+// format/xml/xml_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject"
+	"github.com/streamwest-1629/convertobject/format/xml"
+)
+
+type xmlPrice struct {
+	Currency string  `map-to:"currency"`
+	Amount   float64 `map-to:"value"`
+}
+
+type xmlItem struct {
+	SKU   string   `map-to:"sku!"`
+	Name  string   `map-to:"name"`
+	Price xmlPrice `map-to:"price"`
+}
+
+type xmlOrder struct {
+	ID    int       `map-to:"id!"`
+	Items []xmlItem `map-to:"item"`
+	Tags  []string  `map-to:"tags"`
+	Note  string    `map-to:"note"`
+}
+
+func TestParse(t *testing.T) {
+
+	input := `<?xml version="1.0"?>
+<order id="7" xmlns="urn:example">
+	<!-- comment -->
+	<item sku="a1"><name>Pen</name></item>
+	<item sku="b2"><name>Ink</name></item>
+	<price currency="EUR">9.5</price>
+	<note>  fragile  </note>
+	<empty/>
+</order>`
+
+	src, err := xml.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := map[string]interface{}{
+		"id": "7",
+		"item": []interface{}{
+			map[string]interface{}{"sku": "a1", "name": "Pen"},
+			map[string]interface{}{"sku": "b2", "name": "Ink"},
+		},
+		"price": map[string]interface{}{"currency": "EUR", "#text": "9.5"},
+		"note":  "fragile",
+		"empty": "",
+	}
+	if !reflect.DeepEqual(src, want) {
+		t.Errorf("unexpected result: %#v", src)
+	}
+}
+
+func TestDecoderOptions(t *testing.T) {
+
+	d := xml.NewDecoder()
+	d.TextKey, d.AttrPrefix = "value", "@"
+
+	src, err := d.Parse(strings.NewReader(`<price currency="EUR">9.5</price>`))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if want := map[string]interface{}{"@currency": "EUR", "value": "9.5"}; !reflect.DeepEqual(src, want) {
+		t.Errorf("unexpected result: %#v", src)
+	}
+}
+
+func TestDecode(t *testing.T) {
+
+	// single item and wrapped tags are lists, guided by the destination structure
+	input := `<order id="7">
+	<item sku="a1">
+		<name>Pen</name>
+		<price currency="EUR"><value>9.5</value></price>
+	</item>
+	<tags><tag>office</tag><tag>blue</tag></tags>
+</order>`
+
+	d := xml.NewDecoder()
+	dst := xmlOrder{}
+	if err := xml.Decode(strings.NewReader(input), &dst); err != nil {
+		t.Fatal(err.Error())
+	}
+	want := xmlOrder{
+		ID:    7,
+		Items: []xmlItem{{SKU: "a1", Name: "Pen", Price: xmlPrice{Currency: "EUR", Amount: 9.5}}},
+		Tags:  []string{"office", "blue"},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("unexpected result: %#v", dst)
+	}
+
+	// without the structure, the single item is a map
+	if err := d.Decode(strings.NewReader(input), &xmlOrder{}); err == nil {
+		t.Error("must be error without the structure")
+	}
+}
+
+func TestDecodeWrapperAttribute(t *testing.T) {
+
+	d := xml.NewDecoder()
+	d.Struct = convertobject.CompileStructForce(xmlOrder{})
+
+	// attributes are not wrapped items, even when the element has only one
+	for input, want := range map[string]interface{}{
+		`<order id="7"><tags id="1"/></order>`:                   []interface{}{map[string]interface{}{"id": "1"}},
+		`<order id="7"><tags id="1"><tag>a</tag></tags></order>`: []interface{}{map[string]interface{}{"id": "1", "tag": "a"}},
+		`<order id="7"><tags><tag>a</tag></tags></order>`:        []interface{}{"a"},
+	} {
+		if src, err := d.Parse(strings.NewReader(input)); err != nil {
+			t.Errorf("%s: %s", input, err.Error())
+		} else if !reflect.DeepEqual(src["tags"], want) {
+			t.Errorf("%s: want tags %#v, has %#v", input, want, src["tags"])
+		}
+	}
+
+	if err := xml.Decode(strings.NewReader(`<order id="7"><tags id="1"/></order>`), &xmlOrder{}); err == nil {
+		t.Error("attribute of tags: want error")
+	}
+}
+
+func TestParseError(t *testing.T) {
+
+	if _, err := xml.Parse(strings.NewReader(`<order><item></order>`)); err == nil {
+		t.Error("must be error")
+	}
+	if _, err := xml.Parse(strings.NewReader(`<!-- only comment -->`)); err == nil {
+		t.Error("must be error")
+	}
+}
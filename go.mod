@@ -1,3 +1,3 @@
 module github.com/streamwest-1629/convertobject
 
-go 1.16
+go 1.17
@@ -0,0 +1,378 @@
+// json.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+type (
+	// The error of reading JSON, which stops decoding even in the multi-error mode.
+	jsonReadError struct {
+		err error
+	}
+)
+
+// Decode JSON from the reader into the destination pointer, without building the whole map.
+//
+// The result is the same as json.Unmarshal into interface{} followed by DirectConvert into the zero destination:
+// numbers are float64, and the same label semantics, required checks and coercion rules are applied.
+// Members of repeated keys are reset before each occurrence, so that the last one takes effect.
+func DecodeJSON(r io.Reader, dst interface{}) error {
+	return DecodeJSONWith(r, dst, Options{})
+}
+
+// Decode JSON from the reader into the destination pointer with the options, same as DecodeJSON otherwise.
+func DecodeJSONWith(r io.Reader, dst interface{}, opts Options) error {
+
+	destination := reflect.ValueOf(dst)
+	if destination.Kind() != reflect.Ptr || destination.IsNil() {
+		panic("destination of DecodeJSON must be non-nil pointer")
+	}
+
+	convert, err := selectConvert(destination.Type().Elem(), &PreCompiled)
+	if err != nil {
+		return err
+	}
+	return unwrapReadError(decodeJSON(json.NewDecoder(r), convert, destination.Elem(), "", &opts))
+}
+
+// Decode the next JSON value of the decoder into the destination structure.
+// Numbers are float64 or json.Number, as the decoder is configured with UseNumber.
+func (s *Struct) DecodeJSON(dec *json.Decoder, dst interface{}) error {
+	return s.DecodeJSONWith(dec, dst, Options{})
+}
+
+// Decode the next JSON value of the decoder into the destination structure with the options.
+func (s *Struct) DecodeJSONWith(dec *json.Decoder, dst interface{}, opts Options) error {
+
+	destination := reflect.ValueOf(dst)
+	if destination.Kind() != reflect.Ptr || destination.IsNil() || destination.Type().Elem() != s.Type {
+		panic(util.ErrInvalidType("", reflect.New(s.Type).Interface(), dst))
+	}
+	return unwrapReadError(decodeJSON(dec, s, destination.Elem(), "", &opts))
+}
+
+func decodeJSON(dec *json.Decoder, convert Convert, val reflect.Value, property string, opts *Options) error {
+
+	if tok, err := readJSON(dec); err != nil {
+		return err
+	} else {
+		return decodeJSONToken(dec, tok, convert, val, property, opts)
+	}
+}
+
+// Decode the JSON value starting with the token into the addressable value.
+// Structures and slices are decoded member by member, and other values are built and converted.
+func decodeJSONToken(dec *json.Decoder, tok json.Token, convert Convert, val reflect.Value, property string, opts *Options) error {
+
+	switch c := convert.(type) {
+	case *Struct:
+		// embedded members share keys with the parent, so that they are converted from the built map
+		if tok == json.Delim('{') && !c.hasEmbed() {
+			return decodeJSONStruct(dec, c, val, property, opts)
+		}
+	case *Slice:
+		if tok == json.Delim('[') {
+			return decodeJSONSlice(dec, c, val, property, opts)
+		}
+	case *Ptr:
+		if tok != nil {
+			if val.IsNil() {
+				val.Set(reflect.New(c.gen))
+			}
+			return decodeJSONToken(dec, tok, c.Internal, val.Elem(), property, opts)
+		}
+	}
+
+	if src, err := buildJSON(dec, tok); err != nil {
+		return err
+	} else {
+		return convertWith(convert, src, val.Addr().Interface(), property, opts)
+	}
+}
+
+func decodeJSONStruct(dec *json.Decoder, c *Struct, val reflect.Value, property string, opts *Options) error {
+
+	if opts.Mode == ModeReset {
+		val.Set(reflect.Zero(val.Type()))
+	}
+
+	errs := util.Errors{}
+	present := make([]bool, len(c.Members))
+	presence := c.presenceOf(val, opts)
+	memberProperty := func(member *Member) string {
+		return member.property(property)
+	}
+
+	for dec.More() {
+
+		keyTok, err := readJSON(dec)
+		if err != nil {
+			return err
+		}
+		at := c.memberAt(keyTok.(string))
+		if at < 0 {
+			if err := skipJSON(dec); err != nil {
+				return err
+			}
+			continue
+		}
+
+		member := &c.Members[at]
+		memProperty := member.property(property)
+		tok, err := readJSON(dec)
+		if err != nil {
+			return err
+		}
+
+		// the last occurrence of repeated keys takes effect, as json.Unmarshal keeps it in maps
+		field := val.Field(member.MemberAt)
+		if present[at] {
+			field.Set(reflect.Zero(field.Type()))
+		}
+		present[at] = true
+		presence.mark(member, tok)
+		if tok == nil {
+			if err = assignNull(field, member.nullMode(opts), memProperty); err == nil {
+				err = member.checkAbsent(field, memProperty)
+			}
+		} else if err = decodeJSONToken(dec, tok, member.Convert, field, memProperty, opts); err == nil {
+			err = member.check(field, memProperty, opts)
+		} else if isReadError(err) {
+			return err
+		}
+
+		if err != nil {
+			if opts.Errors != CollectErrors {
+				return skipRestJSON(dec, true, err)
+			}
+			errs.Append(err)
+		}
+	}
+
+	// consume '}'
+	if _, err := readJSON(dec); err != nil {
+		return err
+	}
+
+	for i := range c.Members {
+
+		member := &c.Members[i]
+		if member.Embed || present[i] || opts.Partial {
+			continue
+		}
+
+		err := error(nil)
+		if member.Required {
+			err = util.ErrCannotFound(member.property(property))
+		} else {
+			err = member.checkAbsent(val.Field(member.MemberAt), member.property(property))
+		}
+		if err != nil {
+			if opts.Errors != CollectErrors {
+				return err
+			}
+			errs.Append(err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return c.validate(val, present, property, memberProperty, opts)
+}
+
+func decodeJSONSlice(dec *json.Decoder, s *Slice, val reflect.Value, property string, opts *Options) error {
+
+	// existing elements are decoded into by index on ModeMerge
+	prev := val.Slice(0, val.Len())
+	result := reflect.MakeSlice(reflect.SliceOf(s.gen), 0, val.Len())
+	if opts.Mode == ModeMerge {
+		result = reflect.AppendSlice(result, prev)
+	}
+
+	errs := util.Errors{}
+	for i := 0; dec.More(); i++ {
+
+		if i >= result.Len() {
+			result = reflect.Append(result, reflect.Zero(s.gen))
+		}
+		elemProperty := property + "[" + strconv.Itoa(i) + "]"
+
+		tok, err := readJSON(dec)
+		if err != nil {
+			return err
+		} else if tok == nil {
+			err = assignNull(result.Index(i), opts.Null, elemProperty)
+		} else if err = decodeJSONToken(dec, tok, s.Internal, result.Index(i), elemProperty, opts); err != nil && isReadError(err) {
+			return err
+		}
+
+		if err != nil {
+			if opts.Errors != CollectErrors {
+				return skipRestJSON(dec, false, err)
+			}
+			errs.Append(err)
+		}
+	}
+
+	// consume ']'
+	if _, err := readJSON(dec); err != nil {
+		return err
+	}
+	val.Set(result)
+	return errs.Err()
+}
+
+// Build the JSON value starting with the token, as json.Unmarshal into interface{}.
+func buildJSON(dec *json.Decoder, tok json.Token) (interface{}, error) {
+
+	switch tok {
+	case json.Delim('{'):
+		result := make(map[string]interface{})
+		for dec.More() {
+			key, err := readJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			if val, err := nextJSON(dec); err != nil {
+				return nil, err
+			} else {
+				result[key.(string)] = val
+			}
+		}
+		_, err := readJSON(dec)
+		return result, err
+
+	case json.Delim('['):
+		result := []interface{}{}
+		for dec.More() {
+			if val, err := nextJSON(dec); err != nil {
+				return nil, err
+			} else {
+				result = append(result, val)
+			}
+		}
+		_, err := readJSON(dec)
+		return result, err
+
+	case json.Delim('}'), json.Delim(']'):
+		return nil, errors.New("unexpected JSON delimiter " + tok.(json.Delim).String())
+
+	default:
+		return tok, nil
+	}
+}
+
+func nextJSON(dec *json.Decoder) (interface{}, error) {
+	if tok, err := readJSON(dec); err != nil {
+		return nil, err
+	} else {
+		return buildJSON(dec, tok)
+	}
+}
+
+// Skip the next JSON value.
+func skipJSON(dec *json.Decoder) error {
+
+	depth := 0
+	for {
+		tok, err := readJSON(dec)
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// Skip the rest of the object or the array including its closing delimiter, and return the error of conversion.
+// The decoder is left at the next value, errors of reading are returned instead.
+func skipRestJSON(dec *json.Decoder, object bool, convertErr error) error {
+
+	for dec.More() {
+		if object {
+			if _, err := readJSON(dec); err != nil {
+				return err
+			}
+		}
+		if err := skipJSON(dec); err != nil {
+			return err
+		}
+	}
+	if _, err := readJSON(dec); err != nil {
+		return err
+	}
+	return convertErr
+}
+
+func readJSON(dec *json.Decoder) (json.Token, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, &jsonReadError{err: err}
+	}
+	return tok, nil
+}
+
+func (e *jsonReadError) Error() string {
+	return e.err.Error()
+}
+
+func isReadError(err error) bool {
+	_, ok := err.(*jsonReadError)
+	return ok
+}
+
+// Returns the original error of reading JSON.
+func unwrapReadError(err error) error {
+	if readErr, ok := err.(*jsonReadError); ok {
+		return readErr.err
+	}
+	return err
+}
+
+// Index of the member of the keyname, or -1 when not found.
+func (c *Struct) memberAt(keyname string) int {
+	for i := range c.Members {
+		if member := &c.Members[i]; !member.Embed && member.Keyname == keyname {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *Struct) hasEmbed() bool {
+	for i := range c.Members {
+		if c.Members[i].Embed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,244 @@
+// json_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject"
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+type JSONBase struct {
+	ID int64 `map-to:"id!"`
+}
+
+type jsonItem struct {
+	Name  string   `map-to:"name!"`
+	Price float64  `map-to:"price,min=0"`
+	Tags  []string `map-to:"tags"`
+	Note  *string  `map-to:"note"`
+}
+
+type jsonOrder struct {
+	JSONBase `map-to:"<-"`
+	Items    []jsonItem             `map-to:"items"`
+	Owner    *jsonItem              `map-to:"owner"`
+	Count    int                    `map-to:"count"`
+	Paid     bool                   `map-to:"paid"`
+	Extra    map[string]interface{} `map-to:"extra"`
+	Raw      []byte                 `map-to:"raw,encoding=base64"`
+	Presence convertobject.Presence `map-to:"<presence>"`
+}
+
+type jsonBenchOrder struct {
+	ID    int64      `map-to:"id!"`
+	Items []jsonItem `map-to:"items"`
+	Count int        `map-to:"count"`
+	Paid  bool       `map-to:"paid"`
+}
+
+type jsonBenchCatalog struct {
+	Orders []jsonBenchOrder `map-to:"orders"`
+	Title  string           `map-to:"title!"`
+}
+
+type jsonCatalog struct {
+	Orders []jsonOrder `map-to:"orders"`
+	Title  string      `map-to:"title!"`
+}
+
+// Decode with DecodeJSON and with json.Unmarshal followed by DirectConvert, and compare results.
+func decodeBoth(t *testing.T, input string, dst1, dst2 interface{}, opts convertobject.Options) (err1, err2 error) {
+
+	err1 = convertobject.DecodeJSONWith(strings.NewReader(input), dst1, opts)
+
+	var src interface{}
+	if err := json.Unmarshal([]byte(input), &src); err != nil {
+		t.Fatal(err.Error())
+	}
+	err2 = convertobject.ConvertWith(src, dst2, opts)
+
+	if !reflect.DeepEqual(dst1, dst2) {
+		t.Errorf("results are different:\n%#v\n%#v", dst1, dst2)
+	}
+	return
+}
+
+func TestDecodeJSON(t *testing.T) {
+
+	input := `{
+	"title": "catalog",
+	"unknown": {"deep": [1, {"x": null}]},
+	"orders": [
+		{
+			"id": 1,
+			"items": [{"name": "a", "price": 1.5, "tags": ["x", "y"]}, {"name": "b", "note": "fragile"}],
+			"owner": {"name": "owner", "note": null},
+			"count": "3",
+			"paid": "yes",
+			"extra": {"k": [1, "v"]},
+			"raw": "AQID"
+		},
+		{"id": "2", "owner": null}
+	]
+}`
+	dst1, dst2 := jsonCatalog{}, jsonCatalog{}
+	if err1, err2 := decodeBoth(t, input, &dst1, &dst2, convertobject.Options{}); err1 != nil || err2 != nil {
+		t.Fatalf("unexpected errors: %v, %v", err1, err2)
+	}
+	if dst1.Orders[0].Count != 3 || !dst1.Orders[0].Paid || !bytes.Equal(dst1.Orders[0].Raw, []byte{1, 2, 3}) ||
+		dst1.Orders[1].ID != 2 || !dst1.Orders[1].Presence.IsNull("owner") {
+		t.Errorf("unexpected result: %#v", dst1)
+	}
+
+	bench1, bench2 := jsonBenchCatalog{}, jsonBenchCatalog{}
+	if err1, err2 := decodeBoth(t, string(benchmarkJSONInput()), &bench1, &bench2, convertobject.Options{}); err1 != nil || err2 != nil {
+		t.Fatalf("unexpected errors: %v, %v", err1, err2)
+	}
+}
+
+func TestDecodeJSONRepeatedKeys(t *testing.T) {
+
+	input := `{"title": "a", "orders": [{"id": 1, "owner": {"name": "x", "note": "n"}, "owner": {"name": "y"}, "items": [{"name": "i"}], "items": []}], "title": "b"}`
+	dst1, dst2 := jsonCatalog{}, jsonCatalog{}
+	if err1, err2 := decodeBoth(t, input, &dst1, &dst2, convertobject.Options{}); err1 != nil || err2 != nil {
+		t.Fatalf("unexpected errors: %v, %v", err1, err2)
+	}
+	if owner := dst1.Orders[0].Owner; dst1.Title != "b" || owner.Name != "y" || owner.Note != nil || len(dst1.Orders[0].Items) != 0 {
+		t.Errorf("unexpected result: %#v", dst1)
+	}
+}
+
+func TestDecodeJSONErrors(t *testing.T) {
+
+	input := `{"orders": [{"items": [{"price": -1}, {"name": 1}]}, {"id": 1, "count": "many"}]}`
+	dst1, dst2 := jsonCatalog{}, jsonCatalog{}
+	err1, err2 := decodeBoth(t, input, &dst1, &dst2, convertobject.Options{Errors: convertobject.CollectErrors})
+
+	properties := func(err error) []string {
+		result := []string{}
+		for _, err := range err.(util.Errors) {
+			result = append(result, err.(util.PropertyError).Property())
+		}
+		return result
+	}
+	want := []string{"orders[0].id", "orders[0].items[0].name", "orders[0].items[0].price", "orders[1].count", "title"}
+	if has := properties(err1); !reflect.DeepEqual(has, want) {
+		t.Errorf("want %v, has %v", want, has)
+	}
+	if len(properties(err2)) != len(want) {
+		t.Errorf("two-step errors: %v", err2)
+	}
+}
+
+func TestDecodeJSONSyntaxError(t *testing.T) {
+
+	dst := jsonCatalog{}
+	err := convertobject.DecodeJSON(strings.NewReader(`{"title": "x", "orders": [{"id": }]}`), &dst)
+	if _, ok := err.(*json.SyntaxError); !ok {
+		t.Errorf("unexpected error: %#v", err)
+	}
+}
+
+func TestStructDecodeJSON(t *testing.T) {
+
+	dec := json.NewDecoder(strings.NewReader(`{"name": "a", "price": 12345678901234567890} {"name": "b"}`))
+	dec.UseNumber()
+	compiled := convertobject.CompileStructForce(&jsonItem{})
+
+	items := []jsonItem{}
+	for dec.More() {
+		item := jsonItem{}
+		if err := compiled.DecodeJSON(dec, &item); err != nil {
+			t.Fatal(err.Error())
+		}
+		items = append(items, item)
+	}
+	if len(items) != 2 || items[0].Price != 12345678901234567890 || items[1].Name != "b" {
+		t.Errorf("unexpected result: %#v", items)
+	}
+}
+
+func TestStructDecodeJSONContinue(t *testing.T) {
+
+	dec := json.NewDecoder(strings.NewReader(`{"orders": [{"id": "x", "items": [{"price": -1}, {}]}], "title": "first"} ` +
+		`{"orders": [{"items": [{"name": "a", "tags": [1, {}], "note": "n"}]}], "title": "second"} ` +
+		`{"orders": [{"id": 2}], "title": "third"}`))
+	compiled := convertobject.CompileStructForce(&jsonCatalog{})
+
+	titles := []string{}
+	for dec.More() {
+		catalog := jsonCatalog{}
+		if err := compiled.DecodeJSON(dec, &catalog); err == nil {
+			titles = append(titles, catalog.Title)
+		} else if _, ok := err.(util.PropertyError); !ok {
+			t.Fatalf("want conversion error, has %v", err)
+		}
+	}
+	if want := []string{"third"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("want %v, has %v", want, titles)
+	}
+}
+
+func benchmarkJSONInput() []byte {
+
+	buf := bytes.NewBufferString(`{"title": "bench", "orders": [`)
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(`{"id": ` + strconv.Itoa(i) + `, "count": 3, "paid": true, "items": [` +
+			`{"name": "a", "price": 1.5, "tags": ["x", "y", "z"]}, {"name": "b", "price": 2, "note": "n"}]}`)
+	}
+	buf.WriteString(`]}`)
+	return buf.Bytes()
+}
+
+func BenchmarkDecodeJSON(b *testing.B) {
+
+	input := benchmarkJSONInput()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dst := jsonBenchCatalog{}
+		if err := convertobject.DecodeJSON(bytes.NewReader(input), &dst); err != nil {
+			b.Fatal(err.Error())
+		}
+	}
+}
+
+func BenchmarkUnmarshalDirectConvert(b *testing.B) {
+
+	input := benchmarkJSONInput()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var src interface{}
+		if err := json.Unmarshal(input, &src); err != nil {
+			b.Fatal(err.Error())
+		}
+		dst := jsonBenchCatalog{}
+		if err := convertobject.DirectConvert(src, &dst); err != nil {
+			b.Fatal(err.Error())
+		}
+	}
+}
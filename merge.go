@@ -0,0 +1,332 @@
+// merge.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+const (
+	// Label option of the merge policy of slices and maps: replace, append or merge.
+	// Slices are replaced and maps are merged by default.
+	OptionMerge = `merge`
+	// Label option of the keyname, which identifies elements of slices merged with merge policy.
+	// For example, `map-to:"servers,merge=merge,mergekey=name"` merges elements whose name are the same.
+	OptionMergeKey = `mergekey`
+
+	// Later layer's value replaces the value.
+	MergeReplace = `replace`
+	// Later layer's elements are appended to the slice.
+	MergeAppend = `append`
+	// Later layer's entries are merged into the map, or elements with the same key into the slice.
+	MergeDeep = `merge`
+)
+
+// Convert layers into the destination, after merging them in order.
+// Later layers take precedence over earlier ones.
+// Returned origins are available even when the conversion fails.
+func ConvertLayers(dst interface{}, layers ...Layer) (origins Origins, err error) {
+
+	if merged, origins, err := Merge(dst, layers...); err != nil {
+		return origins, err
+	} else if compiled, err := CompileStruct(dst); err != nil {
+		return origins, err
+	} else {
+		return origins, compiled.Convert(merged, dst, "")
+	}
+}
+
+// Merge layers deeply into a source map for the structure type of target, in order.
+// Members of structures are merged recursively, and slices and maps follow their merge policy.
+// Returned origins record which layer supplied each final value.
+func Merge(target interface{}, layers ...Layer) (merged map[string]interface{}, origins Origins, err error) {
+
+	compiled, err := CompileStruct(target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged, origins = make(map[string]interface{}), make(Origins)
+	for _, layer := range layers {
+		if layer.Source == nil {
+			continue
+		} else if src, ok := toStringMap(layer.Source); !ok {
+			return nil, origins, util.ErrAtProperty(layer.Name, util.ErrInvalidType("", &map[string]interface{}{}, layer.Source))
+		} else if err := mergeStruct(compiled, merged, src, layer.Name, "", origins); err != nil {
+			return nil, origins, util.ErrAtProperty(layer.Name, err)
+		}
+	}
+	return merged, origins, nil
+}
+
+// Lookup the layer which supplied the value of the property.
+// When the property is supplied as a part of its parent, returns the parent's layer.
+func (o Origins) Lookup(property string) (layer string, exist bool) {
+
+	for len(property) > 0 {
+		if layer, exist = o[property]; exist {
+			return layer, true
+		} else if at := strings.LastIndexAny(property, ".["); at < 0 {
+			break
+		} else {
+			property = property[:at]
+		}
+	}
+	return "", false
+}
+
+func mergeStruct(s *Struct, dst, src map[string]interface{}, layer, property string, origins Origins) error {
+
+	for i := range s.Members {
+
+		member := &s.Members[i]
+		if member.Embed {
+			if embedded := structOf(member.Convert); embedded != nil {
+				if err := mergeStruct(embedded, dst, src, layer, property, origins); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		val, exist := src[member.Keyname]
+		if !exist {
+			continue
+		}
+
+		memProperty := joinProperty(property, member.Keyname)
+		fieldType := s.Type.Field(member.MemberAt).Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if internal := structOf(member.Convert); internal != nil {
+			if srcMap, ok := toStringMap(val); ok {
+				// merge into a copy, because the previous map may be a part of an earlier layer, such as an element of slices
+				dstMap := make(map[string]interface{})
+				if prev, ok := toStringMap(dst[member.Keyname]); ok {
+					for key, elem := range prev {
+						dstMap[key] = elem
+					}
+				} else {
+					origins.clear(memProperty)
+				}
+				dst[member.Keyname] = dstMap
+				if err := mergeStruct(internal, dstMap, srcMap, layer, memProperty, origins); err != nil {
+					return err
+				}
+				continue
+			}
+		} else if fieldType.Kind() == reflect.Slice && !isBytes(fieldType) {
+			if merged, err := mergeSlice(member, dst[member.Keyname], val, layer, memProperty, origins); err != nil {
+				return err
+			} else {
+				dst[member.Keyname] = merged
+				continue
+			}
+		} else if fieldType.Kind() == reflect.Map && member.Options[OptionMerge] != MergeReplace {
+			if srcMap, ok := toStringMap(val); ok {
+				dstMap := make(map[string]interface{})
+				if prev, ok := toStringMap(dst[member.Keyname]); ok {
+					for key, elem := range prev {
+						dstMap[key] = elem
+					}
+				} else {
+					origins.clear(memProperty)
+				}
+				for key, elem := range srcMap {
+					dstMap[key] = elem
+					origins[memProperty+"."+key] = layer
+				}
+				dst[member.Keyname] = dstMap
+				continue
+			}
+		}
+
+		// replace the value
+		origins.clear(memProperty)
+		origins[memProperty] = layer
+		dst[member.Keyname] = val
+	}
+	return nil
+}
+
+func mergeSlice(member *Member, prev, val interface{}, layer, property string, origins Origins) (interface{}, error) {
+
+	elems, ok := val.([]interface{})
+	prevElems, prevOk := prev.([]interface{})
+
+	switch policy := member.Options[OptionMerge]; {
+	case !ok || !prevOk || policy == "" || policy == MergeReplace:
+		origins.clear(property)
+		origins[property] = layer
+		return val, nil
+
+	case policy == MergeAppend:
+		merged := append(append([]interface{}{}, prevElems...), elems...)
+		for i := range elems {
+			origins[property+"["+strconv.Itoa(len(prevElems)+i)+"]"] = layer
+		}
+		return merged, nil
+
+	case policy == MergeDeep:
+		keyname := member.Options[OptionMergeKey]
+		internal := (*Struct)(nil)
+		if slice, ok := member.Convert.(*Slice); ok {
+			internal = structOf(slice.Internal)
+		}
+
+		merged := append([]interface{}{}, prevElems...)
+		for _, elem := range elems {
+
+			elemMap, ok := toStringMap(elem)
+			at := -1
+			if ok && internal != nil && len(keyname) > 0 {
+				for j, prevElem := range merged {
+					if prevMap, ok := toStringMap(prevElem); ok && sameKey(prevMap[keyname], elemMap[keyname]) {
+						at = j
+						break
+					}
+				}
+			}
+
+			if at < 0 {
+				origins[property+"["+strconv.Itoa(len(merged))+"]"] = layer
+				merged = append(merged, elem)
+				continue
+			}
+
+			// merge into a copy of the element with the same key, and members are copied by mergeStruct at each level
+			dstMap := make(map[string]interface{})
+			prevMap, _ := toStringMap(merged[at])
+			for key, v := range prevMap {
+				dstMap[key] = v
+			}
+			if err := mergeStruct(internal, dstMap, elemMap, layer, property+"["+strconv.Itoa(at)+"]", origins); err != nil {
+				return nil, err
+			}
+			merged[at] = dstMap
+		}
+		return merged, nil
+
+	default:
+		return nil, util.ErrAtProperty(property, fmt.Errorf("not supported merge policy: %s", policy))
+	}
+}
+
+// Check merge and mergekey options of the member whose type is __type.
+func compileMergePolicy(__type reflect.Type, options map[string]string) error {
+
+	policy, exist := options[OptionMerge]
+	keyname, keyExist := options[OptionMergeKey]
+	for __type.Kind() == reflect.Ptr {
+		__type = __type.Elem()
+	}
+	isSlice := __type.Kind() == reflect.Slice && !isBytes(__type)
+
+	switch {
+	case !exist && !keyExist:
+		return nil
+	case !exist:
+		return errors.New(OptionMergeKey + " option requires " + OptionMerge + "=" + MergeDeep)
+	case policy != MergeReplace && policy != MergeAppend && policy != MergeDeep:
+		return errors.New("not supported merge policy: " + policy)
+	case !isSlice && __type.Kind() != reflect.Map:
+		return errors.New(OptionMerge + " option is allowed only for slices and maps, but used for " + util.TypeFullname(__type))
+	case policy == MergeAppend && !isSlice:
+		return errors.New(OptionMerge + "=" + MergeAppend + " is allowed only for slices, but used for " + util.TypeFullname(__type))
+	case policy == MergeDeep && isSlice && len(keyname) == 0:
+		return errors.New(OptionMerge + "=" + MergeDeep + " of slices requires " + OptionMergeKey + " option")
+	case keyExist && (policy != MergeDeep || !isSlice):
+		return errors.New(OptionMergeKey + " option is allowed only for slices with " + OptionMerge + "=" + MergeDeep)
+	default:
+		return nil
+	}
+}
+
+// Remove origins of the property and its descendants.
+func (o Origins) clear(property string) {
+	for key := range o {
+		if key == property || strings.HasPrefix(key, property+".") || strings.HasPrefix(key, property+"[") {
+			delete(o, key)
+		}
+	}
+}
+
+// Get compiled structure which the converter converts into, through pointers.
+func structOf(convert Convert) *Struct {
+	switch c := convert.(type) {
+	case *Struct:
+		return c
+	case *Ptr:
+		return structOf(c.Internal)
+	case *Scanner:
+		return c.Struct
+	default:
+		return nil
+	}
+}
+
+// Get the map with string keys, integer keys are formatted in decimal.
+func toStringMap(src interface{}) (map[string]interface{}, bool) {
+	switch mapped := src.(type) {
+	case map[string]interface{}:
+		return mapped, true
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(mapped))
+		for key, val := range mapped {
+			result[fmt.Sprint(key)] = val
+		}
+		return result, true
+	case map[int]interface{}:
+		result := make(map[string]interface{}, len(mapped))
+		for key, val := range mapped {
+			result[strconv.Itoa(key)] = val
+		}
+		return result, true
+	case map[int64]interface{}:
+		result := make(map[string]interface{}, len(mapped))
+		for key, val := range mapped {
+			result[strconv.FormatInt(key, 10)] = val
+		}
+		return result, true
+	case map[string]string:
+		result := make(map[string]interface{}, len(mapped))
+		for key, val := range mapped {
+			result[key] = val
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+func sameKey(a, b interface{}) bool {
+	return a != nil && b != nil && fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func joinProperty(property, keyname string) string {
+	if len(property) > 0 {
+		return property + "." + keyname
+	}
+	return keyname
+}
@@ -0,0 +1,189 @@
+// merge_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject"
+)
+
+type layeredServer struct {
+	Name string `map-to:"name"`
+	Port int    `map-to:"port"`
+}
+
+type layeredConfig struct {
+	Server  layeredServer     `map-to:"server"`
+	Hosts   []string          `map-to:"hosts,merge=append"`
+	Plugins []string          `map-to:"plugins"`
+	Labels  map[string]string `map-to:"labels"`
+	Backend []layeredServer   `map-to:"backends,merge=merge,mergekey=name"`
+}
+
+func TestConvertLayers(t *testing.T) {
+
+	dst := layeredConfig{}
+	origins, err := convertobject.ConvertLayers(&dst,
+		convertobject.Layer{Name: "defaults", Source: map[string]interface{}{
+			"server":   map[string]interface{}{"name": "localhost", "port": 80},
+			"hosts":    []interface{}{"a"},
+			"plugins":  []interface{}{"x"},
+			"labels":   map[string]interface{}{"env": "dev", "team": "core"},
+			"backends": []interface{}{map[string]interface{}{"name": "db", "port": 5432}},
+		}},
+		convertobject.Layer{Name: "file", Source: map[interface{}]interface{}{
+			"server":   map[interface{}]interface{}{"port": 8080},
+			"hosts":    []interface{}{"b"},
+			"plugins":  []interface{}{"y"},
+			"labels":   map[string]interface{}{"env": "prod"},
+			"backends": []interface{}{map[string]interface{}{"name": "db", "port": 6432}, map[string]interface{}{"name": "cache"}},
+		}},
+		convertobject.Layer{Name: "flags", Source: map[string]interface{}{
+			"server": map[string]interface{}{"name": "example.com"},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := layeredConfig{
+		Server:  layeredServer{Name: "example.com", Port: 8080},
+		Hosts:   []string{"a", "b"},
+		Plugins: []string{"y"},
+		Labels:  map[string]string{"env": "prod", "team": "core"},
+		Backend: []layeredServer{{Name: "db", Port: 6432}, {Name: "cache"}},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("want %+v, has %+v", want, dst)
+	}
+
+	for property, layer := range map[string]string{
+		"server.name":       "flags",
+		"server.port":       "file",
+		"hosts[0]":          "defaults",
+		"hosts[1]":          "file",
+		"plugins":           "file",
+		"plugins[0]":        "file",
+		"labels.team":       "defaults",
+		"labels.env":        "file",
+		"backends[0].name":  "file",
+		"backends[0].port":  "file",
+		"backends[1].name":  "file",
+		"backends[1]":       "file",
+		"backends[0]":       "defaults",
+		"server.name.extra": "flags",
+	} {
+		if has, _ := origins.Lookup(property); has != layer {
+			t.Errorf("%s: want from %q, has %q", property, layer, has)
+		}
+	}
+}
+
+func TestMergeKeepsLayers(t *testing.T) {
+
+	type mergedInner struct {
+		A int `map-to:"a"`
+		B int `map-to:"b"`
+	}
+	type mergedElem struct {
+		Name  string      `map-to:"name"`
+		Inner mergedInner `map-to:"inner"`
+	}
+	type mergedConfig struct {
+		Elems []mergedElem `map-to:"elems,merge=merge,mergekey=name"`
+		Inner mergedInner  `map-to:"inner"`
+	}
+
+	first := map[string]interface{}{
+		"elems": []interface{}{map[string]interface{}{"name": "x", "inner": map[string]interface{}{"a": 1}}},
+		"inner": map[string]interface{}{"a": 1},
+	}
+	second := map[string]interface{}{
+		"elems": []interface{}{map[string]interface{}{"name": "x", "inner": map[string]interface{}{"b": 2}}},
+		"inner": map[string]interface{}{"b": 2},
+	}
+	third := map[string]interface{}{
+		"elems": []interface{}{map[string]interface{}{"name": "x", "inner": map[string]interface{}{"a": 3}}},
+	}
+	want := []map[string]interface{}{
+		{
+			"elems": []interface{}{map[string]interface{}{"name": "x", "inner": map[string]interface{}{"a": 1}}},
+			"inner": map[string]interface{}{"a": 1},
+		},
+		{
+			"elems": []interface{}{map[string]interface{}{"name": "x", "inner": map[string]interface{}{"b": 2}}},
+			"inner": map[string]interface{}{"b": 2},
+		},
+		{
+			"elems": []interface{}{map[string]interface{}{"name": "x", "inner": map[string]interface{}{"a": 3}}},
+		},
+	}
+
+	dst := mergedConfig{}
+	if _, err := convertobject.ConvertLayers(&dst,
+		convertobject.Layer{Name: "first", Source: first},
+		convertobject.Layer{Name: "second", Source: second},
+		convertobject.Layer{Name: "third", Source: third},
+	); err != nil {
+		t.Fatal(err.Error())
+	}
+	if want := (mergedConfig{Elems: []mergedElem{{Name: "x", Inner: mergedInner{A: 3, B: 2}}}, Inner: mergedInner{A: 1, B: 2}}); !reflect.DeepEqual(dst, want) {
+		t.Errorf("unexpected result: %+v", dst)
+	}
+	for i, layer := range []map[string]interface{}{first, second, third} {
+		if !reflect.DeepEqual(layer, want[i]) {
+			t.Errorf("layer %d is modified: %v", i, layer)
+		}
+	}
+}
+
+func TestMergePolicyError(t *testing.T) {
+
+	type mergeUnknown struct {
+		Hosts []string `map-to:"hosts,merge=apend"`
+	}
+	type mergeAppendMap struct {
+		Labels map[string]string `map-to:"labels,merge=append"`
+	}
+	type mergeWithoutKey struct {
+		Backend []layeredServer `map-to:"backends,merge=merge"`
+	}
+	type mergeKeyWithoutMerge struct {
+		Backend []layeredServer `map-to:"backends,mergekey=name"`
+	}
+	type mergeScalar struct {
+		Port int `map-to:"port,merge=replace"`
+	}
+
+	for _, c := range []struct {
+		target interface{}
+		want   string
+	}{
+		{mergeUnknown{}, "Hosts: not supported merge policy: apend"},
+		{mergeAppendMap{}, "Labels: merge=append is allowed only for slices, but used for map[string]string"},
+		{mergeWithoutKey{}, "Backend: merge=merge of slices requires mergekey option"},
+		{mergeKeyWithoutMerge{}, "Backend: mergekey option requires merge=merge"},
+		{mergeScalar{}, "Port: merge option is allowed only for slices and maps, but used for int"},
+	} {
+		if _, err := convertobject.CompileStructIndepended(c.target); err == nil {
+			t.Errorf("%T: want error", c.target)
+		} else if err.Error() != c.want {
+			t.Errorf("%T: want %q, has %q", c.target, c.want, err.Error())
+		}
+	}
+}
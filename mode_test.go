@@ -0,0 +1,183 @@
+// mode_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject"
+	"github.com/streamwest-1629/convertobject/standard"
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+type patchItem struct {
+	Name  string `map-to:"name"`
+	Count int    `map-to:"count"`
+}
+
+type patchTarget struct {
+	Title  string            `map-to:"title"`
+	Owner  *patchItem        `map-to:"owner"`
+	Items  []patchItem       `map-to:"items"`
+	Labels map[string]string `map-to:"labels"`
+}
+
+func TestConversionMode(t *testing.T) {
+
+	patch := map[string]interface{}{
+		"owner":  map[string]interface{}{"count": 2},
+		"items":  []interface{}{map[string]interface{}{"count": 5}},
+		"labels": map[string]interface{}{"b": "2"},
+	}
+	existing := func() patchTarget {
+		return patchTarget{
+			Title:  "title",
+			Owner:  &patchItem{Name: "owner", Count: 1},
+			Items:  []patchItem{{Name: "first", Count: 1}, {Name: "second", Count: 1}},
+			Labels: map[string]string{"a": "1"},
+		}
+	}
+
+	for _, c := range []struct {
+		mode convertobject.ConvertMode
+		want patchTarget
+	}{
+		{convertobject.ModeOverwrite, patchTarget{
+			Title:  "title",
+			Owner:  &patchItem{Name: "owner", Count: 2},
+			Items:  []patchItem{{Count: 5}},
+			Labels: map[string]string{"b": "2"},
+		}},
+		{convertobject.ModeMerge, patchTarget{
+			Title:  "title",
+			Owner:  &patchItem{Name: "owner", Count: 2},
+			Items:  []patchItem{{Name: "first", Count: 5}, {Name: "second", Count: 1}},
+			Labels: map[string]string{"a": "1", "b": "2"},
+		}},
+		{convertobject.ModeReset, patchTarget{
+			Owner:  &patchItem{Count: 2},
+			Items:  []patchItem{{Count: 5}},
+			Labels: map[string]string{"b": "2"},
+		}},
+	} {
+		dst := existing()
+		if err := convertobject.ConvertWith(patch, &dst, convertobject.Options{Mode: c.mode}); err != nil {
+			t.Errorf("mode %d: %s", c.mode, err.Error())
+		} else if !reflect.DeepEqual(dst, c.want) {
+			t.Errorf("mode %d: want %+v, has %+v", c.mode, c.want, dst)
+		}
+	}
+}
+
+func TestConversionModeUniform(t *testing.T) {
+
+	type uniformTarget struct {
+		Value patchItem  `map-to:"value"`
+		Ptr   *patchItem `map-to:"ptr"`
+	}
+
+	src := map[string]interface{}{
+		"value": map[string]interface{}{"count": 2},
+		"ptr":   map[string]interface{}{"count": 2},
+	}
+	for _, mode := range []convertobject.ConvertMode{convertobject.ModeOverwrite, convertobject.ModeMerge, convertobject.ModeReset} {
+		dst := uniformTarget{Value: patchItem{Name: "a", Count: 1}, Ptr: &patchItem{Name: "a", Count: 1}}
+		if err := convertobject.ConvertWith(src, &dst, convertobject.Options{Mode: mode}); err != nil {
+			t.Errorf("mode %d: %s", mode, err.Error())
+		} else if dst.Ptr == nil || dst.Value != *dst.Ptr {
+			t.Errorf("mode %d: want the same value, has %+v and %+v", mode, dst.Value, dst.Ptr)
+		}
+	}
+}
+
+func TestNullHandling(t *testing.T) {
+
+	type nullable struct {
+		Owner    *patchItem             `map-to:"owner"`
+		Count    int                    `map-to:"count"`
+		Timeout  int                    `map-to:"timeout,null=zero"`
+		Tags     []string               `map-to:"tags"`
+		Presence convertobject.Presence `map-to:"<presence>"`
+	}
+
+	src := map[string]interface{}{"owner": nil, "timeout": nil, "tags": []interface{}{"a"}}
+	dst := nullable{Owner: &patchItem{}, Timeout: 10}
+	if err := convertobject.DirectConvert(src, &dst); err != nil {
+		t.Fatal(err.Error())
+	} else if dst.Owner != nil || dst.Timeout != 0 {
+		t.Errorf("want null members reset, has %+v", dst)
+	}
+
+	for keyname, want := range map[string]convertobject.PresenceState{
+		"owner":   convertobject.PresenceNull,
+		"timeout": convertobject.PresenceNull,
+		"tags":    convertobject.PresenceSet,
+		"count":   convertobject.PresenceAbsent,
+	} {
+		if has := dst.Presence[keyname]; has != want {
+			t.Errorf("%s: want presence %d, has %d", keyname, want, has)
+		}
+	}
+
+	if err := convertobject.DirectConvert(map[string]interface{}{"count": nil}, &nullable{}); err == nil {
+		t.Error("null int: want error")
+	}
+
+	opts := convertobject.Options{Null: convertobject.NullIsError}
+	if err := convertobject.ConvertWith(map[string]interface{}{"owner": nil}, &nullable{}, opts); err == nil {
+		t.Error("null pointer with NullIsError: want error")
+	}
+	if err := convertobject.ConvertWith(map[string]interface{}{"timeout": nil}, &nullable{}, opts); err != nil {
+		t.Errorf("null option takes precedence: %s", err.Error())
+	}
+	if err := convertobject.DirectConvert(map[string]interface{}{"owner": nil}, &nullable{}); err != nil {
+		t.Errorf("options are given per call: %s", err.Error())
+	}
+}
+
+func TestCoercionOption(t *testing.T) {
+
+	src := map[string]interface{}{"owner": map[string]interface{}{"count": "2"}, "items": []interface{}{map[string]interface{}{"name": 1}}}
+	strict := convertobject.Options{Coercion: standard.CoercionStrict, Errors: convertobject.CollectErrors}
+	if err := convertobject.ConvertWith(src, &patchTarget{}, strict); err == nil {
+		t.Error("strict: want error")
+	} else if errs, ok := err.(util.Errors); !ok || len(errs) != 2 {
+		t.Errorf("strict: want errors of owner.count and items[0].name, has %v", err)
+	}
+
+	dst := patchTarget{}
+	if err := convertobject.ConvertWith(src, &dst, convertobject.Options{Coercion: standard.CoercionLoose}); err != nil {
+		t.Fatal(err.Error())
+	} else if dst.Owner.Count != 2 || dst.Items[0].Name != "1" {
+		t.Errorf("unexpected result: %+v", dst)
+	}
+	if err := convertobject.DirectConvert(src, &patchTarget{}); err != nil {
+		t.Errorf("policies are given per call: %s", err.Error())
+	}
+}
+
+func TestMapOptions(t *testing.T) {
+
+	src := map[string]interface{}{"labels": map[string]interface{}{"a": 1}}
+	if err := convertobject.ConvertWith(src, &patchTarget{}, convertobject.Options{Coercion: standard.CoercionStrict}); err == nil {
+		t.Error("strict map value: want error")
+	}
+	dst := patchTarget{}
+	if err := convertobject.ConvertWith(src, &dst, convertobject.Options{}); err != nil || dst.Labels["a"] != "1" {
+		t.Errorf("loose map value: %v, %v", err, dst.Labels)
+	}
+}
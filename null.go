@@ -0,0 +1,108 @@
+// null.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+const (
+	// Label of the member which records presence of other members, whose type must be Presence.
+	// For example, `map-to:"<presence>"`
+	LabelPresence = `<presence>`
+
+	// Label option of null semantics of the member: nil, zero or error.
+	// For example, `map-to:"timeout,null=zero"`
+	OptionNull = `null`
+)
+
+var (
+	presenceType = reflect.TypeOf(Presence{})
+
+	nullModes = map[string]NullMode{
+		"nil":   NullSetsNil,
+		"zero":  NullZeroes,
+		"error": NullIsError,
+	}
+)
+
+// Reports whether the member of the keyname is set in the source map, with non-null value.
+func (p Presence) IsSet(keyname string) bool {
+	return p[keyname] == PresenceSet
+}
+
+// Reports whether the member of the keyname is set in the source map, with null value.
+func (p Presence) IsNull(keyname string) bool {
+	return p[keyname] == PresenceNull
+}
+
+// Reports whether the member of the keyname is found in the source map, with null or not.
+func (p Presence) Has(keyname string) bool {
+	return p[keyname] != PresenceAbsent
+}
+
+// Record the member is found with the source value, nothing to do for nil Presence.
+func (p Presence) mark(member *Member, src interface{}) {
+	if p == nil {
+		return
+	} else if src == nil {
+		p[member.Keyname] = PresenceNull
+	} else {
+		p[member.Keyname] = PresenceSet
+	}
+}
+
+func compileNullMode(options map[string]string) (mode NullMode, specified bool, err error) {
+
+	if name, exist := options[OptionNull]; !exist {
+		return NullSetsNil, false, nil
+	} else if mode, exist := nullModes[name]; !exist {
+		return NullSetsNil, false, errors.New("not supported null option: " + name)
+	} else {
+		return mode, true, nil
+	}
+}
+
+// Assign null to the destination value, with the null mode.
+func assignNull(dst reflect.Value, mode NullMode, property string) error {
+
+	switch mode {
+	case NullZeroes:
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	case NullSetsNil:
+		switch dst.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		if isNullScanner(dst) {
+			return scanNull(dst, property)
+		}
+	}
+	return util.ErrNull(property, dst.Interface())
+}
+
+// Get the null mode of the member, label option takes precedence over the options.
+func (m *Member) nullMode(opts *Options) NullMode {
+	if m.nullSpecified {
+		return m.null
+	}
+	return opts.Null
+}
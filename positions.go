@@ -0,0 +1,105 @@
+// positions.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+type (
+	// Position of the value in the source file.
+	// Column is 0 when the format reports only lines.
+	Position struct {
+		File   string
+		Line   int
+		Column int
+	}
+
+	// Positions of values, keyed by properties such as `children[0].date`.
+	Positions map[string]Position
+
+	// Source value with positions of its values, attached by format parsers.
+	// Struct, Slice, Ptr and Map converters accept it as the source, and prefix errors with positions.
+	PositionedSource struct {
+		Value     interface{}
+		Positions Positions
+	}
+)
+
+// Attach positions to the source value.
+func WithPositions(src interface{}, positions Positions) *PositionedSource {
+	return &PositionedSource{Value: src, Positions: positions}
+}
+
+func (p Position) String() string {
+
+	position := p.File
+	if len(position) == 0 {
+		position = "<input>"
+	}
+	position += ":" + strconv.Itoa(p.Line)
+	if p.Column > 0 {
+		position += ":" + strconv.Itoa(p.Column)
+	}
+	return position
+}
+
+// Lookup returns the position of the property.
+// When the property has no position, such as missing keys, the position of the nearest parent is returned.
+func (p Positions) Lookup(property string) (position Position, exist bool) {
+
+	for len(property) > 0 {
+		if position, exist = p[property]; exist {
+			return position, true
+		} else if at := strings.LastIndexAny(property, ".["); at < 0 {
+			break
+		} else {
+			property = property[:at]
+		}
+	}
+	position, exist = p[""]
+	return
+}
+
+// Convert the positioned value, and prefix errors with positions of their properties.
+func (s *PositionedSource) convert(c Convert, dst interface{}, property string, opts *Options) error {
+
+	err := convertWith(c, s.Value, dst, property, opts)
+	if errs, ok := err.(util.Errors); ok {
+		result := make(util.Errors, len(errs))
+		for i, err := range errs {
+			result[i] = s.annotate(err, property)
+		}
+		return result
+	}
+	return s.annotate(err, property)
+}
+
+func (s *PositionedSource) annotate(err error, property string) error {
+
+	if err == nil {
+		return nil
+	} else if propErr, ok := err.(util.PropertyError); ok {
+		property = propErr.Property()
+	}
+	if position, exist := s.Positions.Lookup(property); exist {
+		return util.ErrAtPosition(position.String(), err)
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+// positions_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject"
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+type positionChild struct {
+	Name string `map-to:"name!"`
+	Age  int    `map-to:"age"`
+}
+
+type positionConfig struct {
+	Children []positionChild `map-to:"children"`
+}
+
+func TestPositions(t *testing.T) {
+
+	src := map[string]interface{}{
+		"children": []interface{}{
+			map[string]interface{}{"name": "Alice", "age": "ten"},
+			map[string]interface{}{"age": 3},
+		},
+	}
+	positions := convertobject.Positions{
+		"children":        {File: "config.toml", Line: 2, Column: 1},
+		"children[0]":     {File: "config.toml", Line: 2, Column: 13},
+		"children[0].age": {File: "config.toml", Line: 2, Column: 40},
+		"children[1]":     {File: "config.toml", Line: 3, Column: 3},
+	}
+
+	dst := positionConfig{}
+	err := convertobject.ConvertWith(convertobject.WithPositions(src, positions), &dst, convertobject.Options{Errors: convertobject.CollectErrors})
+
+	var errs util.Errors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "config.toml:2:40: children[0].age is invalid number"; !strings.HasPrefix(errs[0].Error(), want) {
+		t.Errorf("want %q, has %q", want, errs[0].Error())
+	}
+	// missing keys are reported at the position of the parent
+	if want := "config.toml:3:3: children[1].name"; !strings.HasPrefix(errs[1].Error(), want) {
+		t.Errorf("want %q, has %q", want, errs[1].Error())
+	}
+	if propErr, ok := errs[1].(util.PropertyError); !ok || propErr.Property() != "children[1].name" {
+		t.Errorf("property is lost: %v", errs[1])
+	}
+}
+
+func TestPositionsLookup(t *testing.T) {
+
+	positions := convertobject.Positions{
+		"a":    {Line: 1},
+		"a[0]": {File: "x.toml", Line: 2, Column: 3},
+	}
+	if has, _ := positions.Lookup("a[0].b.c"); has.String() != "x.toml:2:3" {
+		t.Errorf("unexpected position: %s", has)
+	}
+	if has, _ := positions.Lookup("a[1]"); has.String() != "<input>:1" {
+		t.Errorf("unexpected position: %s", has)
+	}
+	if _, exist := positions.Lookup("b"); exist {
+		t.Error("b must not exist")
+	}
+}
@@ -23,6 +23,14 @@ import (
 )
 
 func (p *Ptr) Convert(src, dst interface{}, property string) error {
+	return p.convertOpts(src, dst, property, &Options{})
+}
+
+func (p *Ptr) convertOpts(src, dst interface{}, property string, opts *Options) error {
+
+	if positioned, ok := src.(*PositionedSource); ok {
+		return positioned.convert(p, dst, property, opts)
+	}
 
 	if destination := reflect.ValueOf(dst).Elem(); destination.Kind() != reflect.Ptr {
 		panic(util.ErrInvalidType(property, reflect.New(p.gen).Addr(), dst))
@@ -30,27 +38,75 @@ func (p *Ptr) Convert(src, dst interface{}, property string) error {
 		if destination.IsNil() {
 			destination.Set(reflect.New(p.gen))
 		}
-		return p.Internal.Convert(src, destination.Interface(), property)
+		return convertWith(p.Internal, src, destination.Interface(), property, opts)
 	}
 }
 
 func (s *Slice) Convert(src, dst interface{}, property string) error {
+	return s.convertOpts(src, dst, property, &Options{})
+}
+
+func (s *Slice) convertOpts(src, dst interface{}, property string, opts *Options) error {
+
+	if positioned, ok := src.(*PositionedSource); ok {
+		return positioned.convert(s, dst, property, opts)
+	}
 
 	if destination := reflect.ValueOf(dst).Elem(); destination.Kind() != reflect.Slice {
 		panic(util.ErrInvalidType(property, reflect.MakeSlice(s.gen, 0, 0).Addr(), dst))
 	} else if buf, ok := src.([]interface{}); ok {
 
-		destination.Set(reflect.MakeSlice(reflect.SliceOf(s.gen), len(buf), len(buf)))
+		length := len(buf)
+		if opts.Mode == ModeMerge && destination.Len() > length {
+			length = destination.Len()
+		}
+
+		// existing elements are converted into by index on ModeMerge
+		prev := destination.Slice(0, destination.Len())
+		destination.Set(reflect.MakeSlice(reflect.SliceOf(s.gen), length, length))
+		if opts.Mode == ModeMerge {
+			reflect.Copy(destination, prev)
+		}
 
+		errs := util.Errors{}
 		for i, val := range buf {
-			ptr := destination.Index(i).Addr().Interface()
-			if err := s.Internal.Convert(val, ptr, property+"["+strconv.Itoa(i)+"]"); err != nil {
-				return err
+			elemProperty := property + "[" + strconv.Itoa(i) + "]"
+			err := error(nil)
+			if val == nil {
+				err = assignNull(destination.Index(i), opts.Null, elemProperty)
+			} else {
+				err = convertWith(s.Internal, val, destination.Index(i).Addr().Interface(), elemProperty, opts)
+			}
+			if err != nil {
+				if opts.Errors != CollectErrors {
+					return err
+				}
+				errs.Append(err)
 			}
 		}
+		return errs.Err()
 	} else {
 		return util.ErrInvalidType(property, src, dst)
 	}
+}
+
+func (m *Map) Convert(src, dst interface{}, property string) error {
+	return m.convertOpts(src, dst, property, &Options{})
+}
+
+func (m *Map) convertOpts(src, dst interface{}, property string, opts *Options) error {
+
+	if positioned, ok := src.(*PositionedSource); ok {
+		return positioned.convert(m, dst, property, opts)
+	}
 
-	return nil
+	if destination := reflect.ValueOf(dst).Elem(); destination.Kind() != reflect.Map {
+		panic(util.ErrInvalidType(property, reflect.New(m.Type).Interface(), dst))
+	} else {
+		// maps are replaced as slices are, and existing entries are kept only on ModeMerge
+		if opts.Mode != ModeMerge {
+			destination.Set(reflect.Zero(m.Type))
+		}
+		return convertWith(m.Internal, src, dst, property, opts)
+	}
 }
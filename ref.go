@@ -0,0 +1,348 @@
+// ref.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Key of reference objects, as {"$ref": "#/definitions/db"}.
+	RefKey = `$ref`
+)
+
+type (
+	// Resolver of reference objects in source maps.
+	//
+	// References are JSON Pointers in URI fragments, as `#/definitions/db`, within the same document,
+	// or relative paths of files in FS followed by optional pointers, as `common.json#/db`.
+	// Other keys of reference objects override keys of referenced maps.
+	// Each target is resolved once, and values referenced more than once are shared in the result.
+	RefResolver struct {
+		// File system of referenced files, or nil to allow only references within the same document.
+		FS fs.FS
+		// Parse referenced files, json.Unmarshal into interface{} when nil.
+		Unmarshal func(data []byte) (interface{}, error)
+	}
+
+	// The error of resolving the reference, with the chain of references followed.
+	RefError struct {
+		PropName string
+		Chain    []string
+		Msg      string
+	}
+
+	// State of resolving references, loaded documents, resolved targets by reference and the chain of following references.
+	refState struct {
+		resolver *RefResolver
+		docs     map[string]interface{}
+		targets  map[string]interface{}
+		chain    []string
+	}
+
+	refDoc struct {
+		file string
+		root interface{}
+	}
+)
+
+// Resolve reference objects within the same document, and returns the copy of the source.
+func ResolveRefs(src interface{}) (interface{}, error) {
+	return (&RefResolver{}).Resolve(src, "")
+}
+
+// Resolve reference objects in the source, whose path in FS is file, or empty when it is not a file.
+// Returns the copy of the source without reference objects.
+func (r *RefResolver) Resolve(src interface{}, file string) (interface{}, error) {
+
+	state := &refState{resolver: r, docs: map[string]interface{}{file: src}, targets: map[string]interface{}{}}
+	return state.resolve(src, refDoc{file: file, root: src}, "")
+}
+
+// Load the file of the path in FS, and resolve reference objects in it.
+func (r *RefResolver) ResolveFile(name string) (interface{}, error) {
+
+	state := &refState{resolver: r, docs: map[string]interface{}{}, targets: map[string]interface{}{}}
+	if src, err := state.load(name, ""); err != nil {
+		return nil, err
+	} else {
+		return state.resolve(src, refDoc{file: name, root: src}, "")
+	}
+}
+
+func (e *RefError) Property() string {
+	return e.PropName
+}
+
+func (e *RefError) Error() string {
+	message := RefKey + " " + strings.Join(e.Chain, " -> ") + ": " + e.Msg
+	if len(e.PropName) > 0 {
+		return e.PropName + ": " + message
+	}
+	return message
+}
+
+func (s *refState) fail(property, msg string) error {
+	return &RefError{PropName: property, Chain: append([]string{}, s.chain...), Msg: msg}
+}
+
+// Copy the value, replacing reference objects with their targets.
+// Keys of maps are resolved in sorted order, so that the same source reports the same error.
+func (s *refState) resolve(val interface{}, doc refDoc, property string) (interface{}, error) {
+
+	if ref, object, ok := refObject(val); ok {
+		return s.follow(ref, object, doc, property)
+	}
+
+	switch v := val.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for _, key := range sortedKeys(v) {
+			if resolved, err := s.resolve(v[key], doc, joinProperty(property, key)); err != nil {
+				return nil, err
+			} else {
+				result[key] = resolved
+			}
+		}
+		return result, nil
+
+	case map[interface{}]interface{}:
+		keys := make([]interface{}, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+
+		result := make(map[interface{}]interface{}, len(v))
+		for _, key := range keys {
+			elem := v[key]
+			if resolved, err := s.resolve(elem, doc, joinProperty(property, fmt.Sprint(key))); err != nil {
+				return nil, err
+			} else {
+				result[key] = resolved
+			}
+		}
+		return result, nil
+
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, elem := range v {
+			if resolved, err := s.resolve(elem, doc, property+"["+strconv.Itoa(i)+"]"); err != nil {
+				return nil, err
+			} else {
+				result[i] = resolved
+			}
+		}
+		return result, nil
+
+	default:
+		return val, nil
+	}
+}
+
+// Follow the reference of the reference object, and returns its resolved target overridden by siblings.
+func (s *refState) follow(ref string, object map[string]interface{}, doc refDoc, property string) (interface{}, error) {
+
+	file, fragment := ref, ""
+	if at := strings.IndexByte(ref, '#'); at >= 0 {
+		file, fragment = ref[:at], ref[at+1:]
+	}
+
+	target := doc
+	if len(file) > 0 {
+		target.file = path.Join(path.Dir(doc.file), file)
+	}
+	id := target.file + "#" + fragment
+
+	for _, followed := range s.chain {
+		if followed == id {
+			s.chain = append(s.chain, id)
+			err := s.fail(property, "cyclic reference")
+			s.chain = s.chain[:len(s.chain)-1]
+			return nil, err
+		}
+	}
+	s.chain = append(s.chain, id)
+	defer func() { s.chain = s.chain[:len(s.chain)-1] }()
+
+	resolved, exist := s.targets[id]
+	if !exist {
+		if len(file) > 0 {
+			if root, err := s.load(target.file, property); err != nil {
+				return nil, err
+			} else {
+				target.root = root
+			}
+		}
+
+		node, err := s.pointer(target, fragment, property)
+		if err != nil {
+			return nil, err
+		}
+		if resolved, err = s.resolve(node, target, property); err != nil {
+			return nil, err
+		}
+		s.targets[id] = resolved
+	}
+	if len(object) <= 1 {
+		return resolved, nil
+	}
+
+	// other keys of the reference object override the copy of the target, which is shared with other references
+	switch target := resolved.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(target)+len(object)-1)
+		for key, elem := range target {
+			copied[key] = elem
+		}
+		resolved = copied
+	case map[interface{}]interface{}:
+		copied := make(map[interface{}]interface{}, len(target)+len(object)-1)
+		for key, elem := range target {
+			copied[key] = elem
+		}
+		resolved = copied
+	default:
+		return nil, s.fail(property, "target with overriding keys must be a map")
+	}
+	for _, key := range sortedKeys(object) {
+		if key == RefKey {
+			continue
+		}
+		overridden, err := s.resolve(object[key], doc, joinProperty(property, key))
+		if err != nil {
+			return nil, err
+		}
+		switch target := resolved.(type) {
+		case map[string]interface{}:
+			target[key] = overridden
+		case map[interface{}]interface{}:
+			target[key] = overridden
+		}
+	}
+	return resolved, nil
+}
+
+// Evaluate the JSON Pointer in the document, following reference objects on the way.
+func (s *refState) pointer(doc refDoc, fragment string, property string) (interface{}, error) {
+
+	pointer, err := url.PathUnescape(fragment)
+	if err != nil {
+		return nil, s.fail(property, "invalid JSON Pointer: "+fragment)
+	} else if len(pointer) == 0 {
+		return doc.root, nil
+	} else if pointer[0] != '/' {
+		return nil, s.fail(property, "JSON Pointer must start with /: "+pointer)
+	}
+
+	node := doc.root
+	for _, token := range strings.Split(pointer[1:], "/") {
+
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		if ref, object, ok := refObject(node); ok {
+			if node, err = s.follow(ref, object, doc, property); err != nil {
+				return nil, err
+			}
+		}
+
+		var exist bool
+		switch v := node.(type) {
+		case map[string]interface{}:
+			node, exist = v[token]
+		case map[interface{}]interface{}:
+			if node, exist = v[token]; !exist {
+				if number, err := strconv.ParseInt(token, 10, 64); err == nil {
+					if node, exist = v[number]; !exist {
+						node, exist = v[int(number)]
+					}
+				}
+			}
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if exist = err == nil && index >= 0 && index < len(v) && (token == "0" || token[0] != '0'); exist {
+				node = v[index]
+			}
+		}
+		if !exist {
+			return nil, s.fail(property, "not found "+token)
+		}
+	}
+	return node, nil
+}
+
+// Load and parse the file in FS, loaded documents are cached.
+func (s *refState) load(name string, property string) (interface{}, error) {
+
+	if root, exist := s.docs[name]; exist {
+		return root, nil
+	} else if s.resolver.FS == nil {
+		return nil, s.fail(property, "references to files are not allowed without FS")
+	}
+
+	data, err := fs.ReadFile(s.resolver.FS, name)
+	if err != nil {
+		return nil, s.fail(property, err.Error())
+	}
+
+	var root interface{}
+	if s.resolver.Unmarshal != nil {
+		root, err = s.resolver.Unmarshal(data)
+	} else {
+		err = json.Unmarshal(data, &root)
+	}
+	if err != nil {
+		return nil, s.fail(property, name+": "+err.Error())
+	}
+	s.docs[name] = root
+	return root, nil
+}
+
+// Get the reference and other keys if the value is a reference object.
+func refObject(val interface{}) (ref string, object map[string]interface{}, ok bool) {
+
+	switch v := val.(type) {
+	case map[string]interface{}:
+		ref, ok = v[RefKey].(string)
+		return ref, v, ok
+	case map[interface{}]interface{}:
+		if ref, ok = v[RefKey].(string); ok {
+			object = make(map[string]interface{}, len(v))
+			for key, elem := range v {
+				object[fmt.Sprint(key)] = elem
+			}
+		}
+		return ref, object, ok
+	default:
+		return "", nil, false
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
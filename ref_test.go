@@ -0,0 +1,178 @@
+// ref_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/streamwest-1629/convertobject"
+)
+
+type refDatabase struct {
+	Host string `map-to:"host!"`
+	Port int    `map-to:"port"`
+}
+
+type refService struct {
+	Name string      `map-to:"name"`
+	DB   refDatabase `map-to:"db"`
+}
+
+type refConfig struct {
+	Services []refService `map-to:"services"`
+}
+
+func mustUnmarshalJSON(t *testing.T, str string) interface{} {
+	var val interface{}
+	if err := json.Unmarshal([]byte(str), &val); err != nil {
+		t.Fatal(err.Error())
+	}
+	return val
+}
+
+func TestResolveRefs(t *testing.T) {
+
+	src := mustUnmarshalJSON(t, `{
+		"definitions": {
+			"db": {"host": "db.local", "port": 5432},
+			"replica": {"$ref": "#/definitions/db", "host": "replica.local"},
+			"a/b": {"host": "escaped"},
+			"list": [{"host": "first"}, {"$ref": "#/definitions/db"}]
+		},
+		"services": [
+			{"name": "api", "db": {"$ref": "#/definitions/db"}},
+			{"name": "report", "db": {"$ref": "#/definitions/replica"}},
+			{"name": "escaped", "db": {"$ref": "#/definitions/a~1b"}},
+			{"name": "through", "db": {"$ref": "#/definitions/list/1"}},
+			{"name": "nested", "db": {"$ref": "#/definitions/replica", "port": 6432}}
+		]
+	}`)
+
+	resolved, err := convertobject.ResolveRefs(src)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	dst := refConfig{}
+	if err := convertobject.DirectConvert(resolved, &dst); err != nil {
+		t.Fatal(err.Error())
+	}
+	want := refConfig{Services: []refService{
+		{Name: "api", DB: refDatabase{Host: "db.local", Port: 5432}},
+		{Name: "report", DB: refDatabase{Host: "replica.local", Port: 5432}},
+		{Name: "escaped", DB: refDatabase{Host: "escaped"}},
+		{Name: "through", DB: refDatabase{Host: "db.local", Port: 5432}},
+		{Name: "nested", DB: refDatabase{Host: "replica.local", Port: 6432}},
+	}}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("unexpected result: %#v", dst)
+	}
+
+	// the source is not modified
+	if _, exist := src.(map[string]interface{})["definitions"].(map[string]interface{})["replica"].(map[string]interface{})["$ref"]; !exist {
+		t.Error("source must not be modified")
+	}
+}
+
+func TestResolveRefsError(t *testing.T) {
+
+	cases := map[string]string{
+		`{"a": {"$ref": "#/b"}, "b": {"$ref": "#/c"}, "c": {"$ref": "#/a"}}`: "a: $ref #/b -> #/c -> #/a -> #/b: cyclic reference",
+		`{"a": {"x": {"$ref": "#/a"}}}`:                                      "a.x.x: $ref #/a -> #/a: cyclic reference",
+		`{"a": {"$ref": "#/b/missing"}, "b": {}}`:                            "a: $ref #/b/missing: not found missing",
+		`{"a": [{"$ref": "#/b"}], "b": {"$ref": "#/c/01"}, "c": [1, 2]}`:     "a[0]: $ref #/b -> #/c/01: not found 01",
+		`{"a": {"$ref": "common.json#/db"}}`:                                 "a: $ref common.json#/db: references to files are not allowed without FS",
+		`{"a": {"$ref": "#/b", "x": 1}, "b": 1}`:                             "a: $ref #/b: target with overriding keys must be a map",
+	}
+	for input, want := range cases {
+		if _, err := convertobject.ResolveRefs(mustUnmarshalJSON(t, input)); err == nil {
+			t.Errorf("%s: must be error", input)
+		} else if err.Error() != want {
+			t.Errorf("%s: want %q, has %q", input, want, err.Error())
+		}
+	}
+}
+
+func TestResolveRefsShared(t *testing.T) {
+
+	// each level references the previous one twice, 2^22 leaves when expanded
+	src := map[string]interface{}{"d0": map[string]interface{}{"host": "leaf"}}
+	for i := 1; i <= 22; i++ {
+		prev := map[string]interface{}{"$ref": "#/d" + strconv.Itoa(i-1)}
+		src["d"+strconv.Itoa(i)] = []interface{}{prev, prev}
+	}
+	src["replica"] = map[string]interface{}{"$ref": "#/d0", "host": "replica"}
+
+	resolved, err := convertobject.ResolveRefs(src)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	node := resolved.(map[string]interface{})["d22"]
+	for i := 22; i > 0; i-- {
+		node = node.([]interface{})[1]
+	}
+	if host := node.(map[string]interface{})["host"]; host != "leaf" {
+		t.Errorf("unexpected leaf: %#v", node)
+	}
+
+	// overriding keys do not modify the shared target
+	result := resolved.(map[string]interface{})
+	if host := result["d0"].(map[string]interface{})["host"]; host != "leaf" {
+		t.Errorf("shared target is modified: %v", host)
+	}
+	if host := result["replica"].(map[string]interface{})["host"]; host != "replica" {
+		t.Errorf("unexpected override: %v", host)
+	}
+}
+
+func TestRefResolverFS(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"config.json":        {Data: []byte(`{"services": [{"name": "api", "db": {"$ref": "shared/db.json#/primary"}}]}`)},
+		"shared/db.json":     {Data: []byte(`{"primary": {"$ref": "hosts.json#/main", "port": 5432}}`)},
+		"shared/hosts.json":  {Data: []byte(`{"main": {"host": "db.local"}}`)},
+		"cyclic/a.json":      {Data: []byte(`{"$ref": "b.json"}`)},
+		"cyclic/b.json":      {Data: []byte(`{"x": {"$ref": "a.json"}}`)},
+		"cyclic/broken.json": {Data: []byte(`{"a": {"$ref": "missing.json"}}`)},
+	}
+	resolver := &convertobject.RefResolver{FS: fsys}
+
+	resolved, err := resolver.ResolveFile("config.json")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	dst := refConfig{}
+	if err := convertobject.DirectConvert(resolved, &dst); err != nil {
+		t.Fatal(err.Error())
+	}
+	if want := (refDatabase{Host: "db.local", Port: 5432}); len(dst.Services) != 1 || dst.Services[0].DB != want {
+		t.Errorf("unexpected result: %#v", dst)
+	}
+
+	if _, err := resolver.ResolveFile("cyclic/a.json"); err == nil {
+		t.Error("must be error")
+	} else if want := "x: $ref cyclic/b.json# -> cyclic/a.json# -> cyclic/b.json#: cyclic reference"; err.Error() != want {
+		t.Errorf("want %q, has %q", want, err.Error())
+	}
+
+	if _, err := resolver.ResolveFile("cyclic/broken.json"); err == nil {
+		t.Error("must be error")
+	}
+}
@@ -0,0 +1,121 @@
+// scanner.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+func (s *Scanner) Convert(src, dst interface{}, property string) error {
+	return s.convertOpts(src, dst, property, &Options{})
+}
+
+func (s *Scanner) convertOpts(src, dst interface{}, property string, opts *Options) error {
+
+	destination := reflect.ValueOf(dst)
+	if destination.Kind() != reflect.Ptr || destination.Type().Elem() != s.Type {
+		panic(util.ErrInvalidType(property, reflect.New(s.Type).Interface(), dst))
+	}
+	destination = destination.Elem()
+
+	if val := reflect.ValueOf(src); val.IsValid() && val.Type() == s.Type {
+		destination.Set(val)
+		return nil
+	} else if valuer, ok := src.(driver.Valuer); ok {
+		// other sql.Null* types and driver.Valuer sources
+		if src, err := valuer.Value(); err != nil {
+			return util.ErrAtProperty(property, err)
+		} else if src == nil {
+			return scanNull(destination, property)
+		} else {
+			return s.convertOpts(src, dst, property, opts)
+		}
+	} else if src == nil {
+		return scanNull(destination, property)
+	}
+
+	// value member and Valid
+	if s.Internal != nil {
+		if err := convertWith(s.Internal, src, destination.Field(0).Addr().Interface(), property, opts); err != nil {
+			return err
+		}
+		destination.Field(1).SetBool(true)
+		return nil
+	}
+
+	// driver values are given to Scan as they are, and others are converted to driver values if possible
+	if converted, err := driver.DefaultParameterConverter.ConvertValue(src); err == nil {
+		src = converted
+	} else if s.Struct != nil {
+		// members of structures are converted from maps
+		return convertWith(s.Struct, src, dst, property, opts)
+	}
+	if err := destination.Addr().Interface().(sql.Scanner).Scan(src); err != nil {
+		return util.ErrAtProperty(property, err)
+	}
+	return nil
+}
+
+// Select converter of the type implementing sql.Scanner with its pointer.
+func selectScanner(__type reflect.Type, cache *map[string]*Struct) (Convert, bool, error) {
+
+	if kind := __type.Kind(); kind == reflect.Ptr || kind == reflect.Interface || !reflect.PtrTo(__type).Implements(scannerType) {
+		return nil, false, nil
+	}
+
+	converter := &Scanner{Type: __type}
+	if isNullShaped(__type) {
+		if internal, err := selectConvert(__type.Field(0).Type, cache); err != nil {
+			return nil, true, err
+		} else {
+			converter.Internal = internal
+		}
+	} else if __type.Kind() == reflect.Struct {
+		if compiled, err := selectStruct(__type, cache); err != nil {
+			return nil, true, err
+		} else {
+			converter.Struct = compiled
+		}
+	}
+	return converter, true, nil
+}
+
+// Reports whether the type is shaped as sql.NullString: exported value member followed by Valid bool.
+func isNullShaped(__type reflect.Type) bool {
+	return __type.Kind() == reflect.Struct && __type.NumField() == 2 &&
+		__type.Field(0).PkgPath == "" && __type.Field(1).Name == "Valid" && __type.Field(1).Type.Kind() == reflect.Bool
+}
+
+// Give null to the addressable value implementing sql.Scanner, which sets Valid of sql.Null* types to false.
+func scanNull(dst reflect.Value, property string) error {
+	if err := dst.Addr().Interface().(sql.Scanner).Scan(nil); err != nil {
+		return util.ErrAtProperty(property, err)
+	}
+	return nil
+}
+
+// Reports whether the addressable value accepts null with sql.Scanner.
+func isNullScanner(dst reflect.Value) bool {
+	return dst.CanAddr() && dst.Kind() != reflect.Ptr && dst.Addr().Type().Implements(scannerType)
+}
@@ -0,0 +1,187 @@
+// scanner_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/streamwest-1629/convertobject"
+)
+
+// Custom scanner which splits comma-separated strings.
+type scannerList []string
+
+func (l *scannerList) Scan(src interface{}) error {
+	switch val := src.(type) {
+	case nil:
+		*l = nil
+	case string:
+		*l = strings.Split(val, ",")
+	case []byte:
+		*l = strings.Split(string(val), ",")
+	default:
+		return errors.New("unsupported source")
+	}
+	return nil
+}
+
+// JSON column, which is scanned from []byte and has labeled members.
+type scannerSettings struct {
+	Theme string `map-to:"theme"`
+	Size  int    `map-to:"size"`
+}
+
+func (s *scannerSettings) Scan(src interface{}) error {
+	buf, ok := src.([]byte)
+	if !ok {
+		return errors.New("want []byte")
+	}
+	var decoded struct {
+		Theme string `json:"theme"`
+		Size  int    `json:"size"`
+	}
+	if err := json.Unmarshal(buf, &decoded); err != nil {
+		return err
+	}
+	s.Theme, s.Size = decoded.Theme, decoded.Size
+	return nil
+}
+
+type scannerUser struct {
+	Name     string          `map-to:"name"`
+	Settings scannerSettings `map-to:"settings"`
+}
+
+type scannerRecord struct {
+	Name    sql.NullString  `map-to:"name"`
+	Count   sql.NullInt64   `map-to:"count"`
+	Enabled sql.NullBool    `map-to:"enabled"`
+	Since   sql.NullTime    `map-to:"since"`
+	Ratio   sql.NullFloat64 `map-to:"ratio"`
+	Tags    scannerList     `map-to:"tags"`
+	Owner   *sql.NullString `map-to:"owner"`
+}
+
+func TestScanner(t *testing.T) {
+
+	src := map[string]interface{}{
+		"name":    "alice",
+		"count":   "12",
+		"enabled": "yes",
+		"since":   "2021-04-01T09:00:00Z",
+		"ratio":   nil,
+		"tags":    "a,b",
+		"owner":   sql.NullString{String: "bob", Valid: true},
+	}
+
+	dst := scannerRecord{Ratio: sql.NullFloat64{Float64: 1, Valid: true}}
+	if err := convertobject.DirectConvert(src, &dst); err != nil {
+		t.Fatal(err.Error())
+	}
+	want := scannerRecord{
+		Name:    sql.NullString{String: "alice", Valid: true},
+		Count:   sql.NullInt64{Int64: 12, Valid: true},
+		Enabled: sql.NullBool{Bool: true, Valid: true},
+		Since:   sql.NullTime{Time: time.Date(2021, 4, 1, 9, 0, 0, 0, time.UTC), Valid: true},
+		Tags:    scannerList{"a", "b"},
+		Owner:   &sql.NullString{String: "bob", Valid: true},
+	}
+	if !dst.Since.Time.Equal(want.Since.Time) {
+		t.Errorf("unexpected since: %v", dst.Since)
+	}
+	dst.Since.Time = want.Since.Time
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("unexpected result: %#v", dst)
+	}
+
+	// other sql.Null* types are converted via their values
+	dst = scannerRecord{}
+	if err := convertobject.DirectConvert(map[string]interface{}{
+		"count": sql.NullString{String: "3", Valid: true},
+		"name":  sql.NullInt64{},
+	}, &dst); err != nil {
+		t.Fatal(err.Error())
+	} else if dst.Count != (sql.NullInt64{Int64: 3, Valid: true}) || dst.Name.Valid {
+		t.Errorf("unexpected result: %#v", dst)
+	}
+}
+
+func TestScannerError(t *testing.T) {
+
+	dst := scannerRecord{}
+	if err := convertobject.DirectConvert(map[string]interface{}{"count": "many"}, &dst); err == nil {
+		t.Error("must be error")
+	}
+	if err := convertobject.DirectConvert(map[string]interface{}{"tags": 1.5}, &dst); err == nil {
+		t.Error("must be error")
+	} else if want := "tags: unsupported source"; err.Error() != want {
+		t.Errorf("want %q, has %q", want, err.Error())
+	}
+}
+
+func TestFlattenScanner(t *testing.T) {
+
+	flat, err := convertobject.Flatten(&scannerRecord{
+		Name:  sql.NullString{String: "alice", Valid: true},
+		Count: sql.NullInt64{Int64: 12, Valid: true},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if want := map[string]interface{}{"name": "alice", "count": int64(12)}; !reflect.DeepEqual(flat, want) {
+		t.Errorf("unexpected result: %#v", flat)
+	}
+}
+
+func TestScannerStruct(t *testing.T) {
+
+	dst := scannerUser{}
+	if err := convertobject.DirectConvert(map[string]interface{}{
+		"name":     "alice",
+		"settings": map[string]interface{}{"theme": "dark", "size": 3},
+	}, &dst); err != nil {
+		t.Fatal(err.Error())
+	} else if want := (scannerUser{Name: "alice", Settings: scannerSettings{Theme: "dark", Size: 3}}); dst != want {
+		t.Errorf("unexpected result: %#v", dst)
+	}
+
+	dst = scannerUser{}
+	if err := convertobject.DirectConvert(map[string]interface{}{"settings": []byte(`{"theme": "light", "size": 5}`)}, &dst); err != nil {
+		t.Fatal(err.Error())
+	} else if want := (scannerSettings{Theme: "light", Size: 5}); dst.Settings != want {
+		t.Errorf("unexpected result: %#v", dst)
+	}
+
+	if err := convertobject.DirectConvert(map[string]interface{}{"settings": 1.5}, &dst); err == nil {
+		t.Error("must be error")
+	} else if want := "settings: want []byte"; err.Error() != want {
+		t.Errorf("want %q, has %q", want, err.Error())
+	}
+
+	flat, err := convertobject.Flatten(&scannerUser{Name: "bob", Settings: scannerSettings{Theme: "dark", Size: 3}})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if want := map[string]interface{}{"name": "bob", "settings.theme": "dark", "settings.size": 3}; !reflect.DeepEqual(flat, want) {
+		t.Errorf("unexpected result: %#v", flat)
+	}
+}
@@ -0,0 +1,224 @@
+// sqlscan/sqlscan.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlscan converts rows of database/sql into structures labeled with `map-to`.
+//
+// Columns are matched to members by keynames, case-insensitively when no keyname matches exactly,
+// and columns without members are ignored. NULL columns are nil, converted as null options of members,
+// and members of sql.Null* types are Valid=false for them.
+// []byte columns are strings, so that numbers sent as text by drivers are converted by the string coercions,
+// except for members of []byte or [N]byte, which receive raw bytes without decoding their encoding option.
+package sqlscan
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/streamwest-1629/convertobject"
+)
+
+type (
+	// Keys of columns for the structure, decided once per query shape.
+	plan struct {
+		compiled *convertobject.Struct
+		keys     []string
+		raw      []bool
+	}
+
+	planKey struct {
+		reflect.Type
+		columns string
+	}
+)
+
+var (
+	// Plans keyed by the structure type and column names.
+	plans sync.Map
+)
+
+// Convert the current row into the destination, which is the pointer of the structure.
+// Call it after rows.Next() returns true.
+func ScanRow(rows *sql.Rows, dst interface{}) error {
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	p, err := planOf(reflect.TypeOf(dst), columns)
+	if err != nil {
+		return err
+	}
+	return p.scan(rows, dst, "")
+}
+
+// Convert all rows into the destination, which is the pointer of the slice of structures or their pointers.
+// Rows are closed after scanning, and errors of conversion have properties of rows, such as `[3].name`.
+func ScanAll(rows *sql.Rows, dst interface{}) error {
+
+	defer rows.Close()
+
+	destination := reflect.ValueOf(dst)
+	if destination.Kind() != reflect.Ptr || destination.Elem().Kind() != reflect.Slice {
+		return errors.New("destination of ScanAll must be the pointer of slice")
+	}
+	slice := destination.Elem()
+	elemType := slice.Type().Elem()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	p, err := planOf(elemType, columns)
+	if err != nil {
+		return err
+	}
+
+	result := reflect.MakeSlice(slice.Type(), 0, 0)
+	for i := 0; rows.Next(); i++ {
+		elem := reflect.New(p.compiled.Type)
+		if err := p.scan(rows, elem.Interface(), "["+strconv.Itoa(i)+"]"); err != nil {
+			return err
+		}
+		if elemType.Kind() == reflect.Ptr {
+			result = reflect.Append(result, elem)
+		} else {
+			result = reflect.Append(result, elem.Elem())
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	slice.Set(result)
+	return nil
+}
+
+// Get the plan of the structure type for columns, which is compiled at first use.
+// Plans are rebuilt when PreCompiled has compiled the structure again, such as after RegisterEnum.
+func planOf(__type reflect.Type, columns []string) (*plan, error) {
+
+	for __type.Kind() == reflect.Ptr {
+		__type = __type.Elem()
+	}
+	if __type.Kind() != reflect.Struct {
+		return nil, errors.New("destination must be the structure, but " + __type.String())
+	}
+
+	compiled, err := convertobject.CompileStruct(reflect.New(__type).Interface())
+	if err != nil {
+		return nil, err
+	}
+	cacheKey := planKey{Type: __type, columns: strings.Join(columns, "\x00")}
+	if cached, exist := plans.Load(cacheKey); exist && cached.(*plan).compiled == compiled {
+		return cached.(*plan), nil
+	}
+
+	keynames, converts := []string{}, map[string]convertobject.Convert{}
+	collectKeynames(compiled, &keynames, converts)
+	p := &plan{compiled: compiled, keys: make([]string, len(columns)), raw: make([]bool, len(columns))}
+	for i, column := range columns {
+		p.keys[i] = matchKeyname(keynames, column)
+		p.raw[i] = isBytes(converts[p.keys[i]])
+	}
+
+	plans.Store(cacheKey, p)
+	return p, nil
+}
+
+// Keynames and converters of members, including members of embedded structures.
+func collectKeynames(s *convertobject.Struct, keynames *[]string, converts map[string]convertobject.Convert) {
+	for i := range s.Members {
+		member := &s.Members[i]
+		if !member.Embed {
+			*keynames = append(*keynames, member.Keyname)
+			converts[member.Keyname] = member.Convert
+			continue
+		}
+		convert := member.Convert
+		if ptr, ok := convert.(*convertobject.Ptr); ok {
+			convert = ptr.Internal
+		}
+		if embedded, ok := convert.(*convertobject.Struct); ok {
+			collectKeynames(embedded, keynames, converts)
+		}
+	}
+}
+
+func matchKeyname(keynames []string, column string) string {
+	for _, keyname := range keynames {
+		if keyname == column {
+			return keyname
+		}
+	}
+	for _, keyname := range keynames {
+		if strings.EqualFold(keyname, column) {
+			return keyname
+		}
+	}
+	return ""
+}
+
+// Scan the current row into the map of keynames, and convert it into the destination.
+func (p *plan) scan(rows *sql.Rows, dst interface{}, property string) error {
+
+	values := make([]interface{}, len(p.keys))
+	targets := make([]interface{}, len(p.keys))
+	for i := range values {
+		targets[i] = &values[i]
+	}
+	if err := rows.Scan(targets...); err != nil {
+		return err
+	}
+
+	src := make(map[string]interface{}, len(p.keys))
+	for i, key := range p.keys {
+		if len(key) > 0 {
+			src[key] = columnValue(values[i], p.raw[i])
+		}
+	}
+	return p.compiled.Convert(src, dst, property)
+}
+
+// Whether the converter converts into bytes, through pointers.
+func isBytes(convert convertobject.Convert) bool {
+	for {
+		switch c := convert.(type) {
+		case *convertobject.Bytes:
+			return true
+		case *convertobject.Ptr:
+			convert = c.Internal
+		default:
+			return false
+		}
+	}
+}
+
+// Convert the column value into the source value of the converter, []byte is kept for bytes members when raw.
+func columnValue(val interface{}, raw bool) interface{} {
+
+	if valuer, ok := val.(driver.Valuer); ok {
+		if v, err := valuer.Value(); err == nil {
+			val = v
+		}
+	}
+	if buf, ok := val.([]byte); ok && !raw {
+		return string(buf)
+	}
+	return val
+}
@@ -0,0 +1,290 @@
+// sqlscan/sqlscan_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlscan_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject"
+	"github.com/streamwest-1629/convertobject/sqlscan"
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+type (
+	// In-process driver returning fixed rows for each query.
+	fakeDriver struct{}
+	fakeConn   struct{}
+	fakeStmt   struct {
+		query string
+	}
+	fakeRows struct {
+		columns []string
+		data    [][]driver.Value
+		at      int
+	}
+)
+
+var fakeTables = map[string]*fakeRows{
+	"users": {
+		columns: []string{"id", "NAME", "age", "score", "nickname", "created_by"},
+		data: [][]driver.Value{
+			{int64(1), []byte("alice"), []byte("42"), 9.5, nil, "admin"},
+			{int64(2), []byte("bob"), int64(17), []byte("7.25"), []byte("b"), "admin"},
+		},
+	},
+	"blobs": {
+		columns: []string{"id", "data", "digest"},
+		data: [][]driver.Value{
+			{int64(1), []byte{0xff, 0x00, 'a'}, []byte{0x01, 0x02}},
+		},
+	},
+	"broken": {
+		columns: []string{"id", "age"},
+		data: [][]driver.Value{
+			{int64(1), int64(3)},
+			{int64(2), []byte("old")},
+		},
+	},
+}
+
+func init() {
+	sql.Register("sqlscan-fake", fakeDriver{})
+}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) { return &fakeConn{}, nil }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{query: query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return 0 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if table, exist := fakeTables[s.query]; exist {
+		return &fakeRows{columns: table.columns, data: table.data}, nil
+	}
+	return nil, errors.New("unknown query: " + s.query)
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.at >= len(r.data) {
+		return io.EOF
+	}
+	copy(dest, r.data[r.at])
+	r.at++
+	return nil
+}
+
+type sqlUser struct {
+	ID       int64   `map-to:"id!"`
+	Name     string  `map-to:"name"`
+	Age      int     `map-to:"age"`
+	Score    float64 `map-to:"score"`
+	Nickname *string `map-to:"nickname"`
+}
+
+func openFake(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlscan-fake", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return db
+}
+
+func TestScanAll(t *testing.T) {
+
+	db := openFake(t)
+	defer db.Close()
+
+	nickname := "b"
+	want := []sqlUser{
+		{ID: 1, Name: "alice", Age: 42, Score: 9.5},
+		{ID: 2, Name: "bob", Age: 17, Score: 7.25, Nickname: &nickname},
+	}
+
+	// twice with the same query shape, to use the cached plan
+	for i := 0; i < 2; i++ {
+		rows, err := db.Query("users")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		users := []sqlUser{}
+		if err := sqlscan.ScanAll(rows, &users); err != nil {
+			t.Fatal(err.Error())
+		} else if !reflect.DeepEqual(users, want) {
+			t.Errorf("unexpected result: %#v", users)
+		}
+	}
+
+	rows, err := db.Query("users")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	pointers := []*sqlUser{}
+	if err := sqlscan.ScanAll(rows, &pointers); err != nil {
+		t.Fatal(err.Error())
+	} else if len(pointers) != 2 || !reflect.DeepEqual(*pointers[1], want[1]) {
+		t.Errorf("unexpected result: %#v", pointers)
+	}
+}
+
+func TestScanRow(t *testing.T) {
+
+	db := openFake(t)
+	defer db.Close()
+
+	rows, err := db.Query("users")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		user := sqlUser{}
+		if err := sqlscan.ScanRow(rows, &user); err != nil {
+			t.Fatal(err.Error())
+		}
+		names = append(names, user.Name)
+	}
+	if strings.Join(names, ",") != "alice,bob" {
+		t.Errorf("unexpected names: %v", names)
+	}
+}
+
+func TestScanAllError(t *testing.T) {
+
+	db := openFake(t)
+	defer db.Close()
+
+	rows, err := db.Query("broken")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	users := []sqlUser{}
+	err = sqlscan.ScanAll(rows, &users)
+	if propErr, ok := err.(util.PropertyError); !ok || propErr.Property() != "[1].age" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(users) != 0 {
+		t.Errorf("destination must not be changed: %#v", users)
+	}
+}
+
+type sqlNullableUser struct {
+	ID       int64          `map-to:"id!"`
+	Age      sql.NullInt64  `map-to:"age"`
+	Nickname sql.NullString `map-to:"nickname"`
+}
+
+func TestScanAllNullable(t *testing.T) {
+
+	db := openFake(t)
+	defer db.Close()
+
+	rows, err := db.Query("users")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	users := []sqlNullableUser{}
+	if err := sqlscan.ScanAll(rows, &users); err != nil {
+		t.Fatal(err.Error())
+	}
+	want := []sqlNullableUser{
+		{ID: 1, Age: sql.NullInt64{Int64: 42, Valid: true}},
+		{ID: 2, Age: sql.NullInt64{Int64: 17, Valid: true}, Nickname: sql.NullString{String: "b", Valid: true}},
+	}
+	if !reflect.DeepEqual(users, want) {
+		t.Errorf("unexpected result: %#v", users)
+	}
+}
+
+type sqlBlob struct {
+	ID     int64    `map-to:"id!"`
+	Data   []byte   `map-to:"data,encoding=base64"`
+	Digest *[2]byte `map-to:"digest,encoding=hex"`
+}
+
+func TestScanAllBytes(t *testing.T) {
+
+	db := openFake(t)
+	defer db.Close()
+
+	rows, err := db.Query("blobs")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	blobs := []sqlBlob{}
+	if err := sqlscan.ScanAll(rows, &blobs); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(blobs) != 1 || string(blobs[0].Data) != "\xff\x00a" || blobs[0].Digest == nil || *blobs[0].Digest != [2]byte{1, 2} {
+		t.Errorf("unexpected result: %#v", blobs)
+	}
+}
+
+type (
+	sqlRole     string
+	sqlRoleUser struct {
+		ID        int64   `map-to:"id!"`
+		CreatedBy sqlRole `map-to:"created_by"`
+	}
+)
+
+func TestScanAllRegisteredLater(t *testing.T) {
+
+	db := openFake(t)
+	defer db.Close()
+
+	scan := func() error {
+		rows, err := db.Query("users")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		users := []sqlRoleUser{}
+		return sqlscan.ScanAll(rows, &users)
+	}
+
+	convertobject.RegisterEnumForce(map[string]interface{}{"admin": sqlRole("admin")}, false)
+	if err := scan(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the name table registered again after the first scan rejects names which are not registered
+	convertobject.RegisterEnumForce(map[string]interface{}{"root": sqlRole("root")}, false)
+	if err := scan(); err == nil {
+		t.Error("enum registered after the first scan must be used")
+	}
+
+	convertobject.RegisterEnumForce(map[string]interface{}{"admin": sqlRole("admin")}, false)
+	convertobject.RegisterValidator(sqlRoleUser{}, func(target interface{}) error {
+		return errors.New("rejected")
+	})
+	if err := scan(); err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Errorf("validator registered after the first scan must be called: %v", err)
+	}
+}
@@ -0,0 +1,284 @@
+// standard/big.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+var (
+	// Precision of *big.Float converted from strings, when the destination has no precision.
+	BigFloatPrec uint = 256
+
+	// Maximum bit length of *big.Int converted from strings and json.Number, longer numbers are out of range.
+	BigIntMaxBits = 1 << 16
+
+	// Maximum absolute exponent of strings and json.Number converted into *big.Float and *big.Rat,
+	// larger exponents are out of range, so that short strings such as "1e1000000000" are not expanded.
+	BigMaxExp int64 = 1 << 16
+
+	jsonNumberMatches = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+)
+
+func ConvertoBigInt(src, dst interface{}, property string) error {
+	return ConvertoBigIntWith(src, dst, property, CoercionDefault)
+}
+
+func ConvertoBigIntWith(src, dst interface{}, property string, policy CoercionPolicy) error {
+	if destination, ok := dst.(*big.Int); !ok {
+		panic(util.ErrInvalidType(property, destination, dst).Error())
+	} else if val, ok := src.(*big.Int); ok {
+		destination.Set(val)
+	} else if val, ok := src.(big.Int); ok {
+		destination.Set(&val)
+	} else if val, ok := integerOf(src); ok {
+		if val < 0 && isUnsigned(src) {
+			var u uint64
+			if err := ConvertoUint64(src, &u, property); err != nil {
+				return err
+			}
+			destination.SetUint64(u)
+		} else {
+			destination.SetInt64(val)
+		}
+	} else if val, ok := src.(json.Number); ok {
+		if val, err := parseIntegral(string(val), property, BigIntMaxBits, *destination); err != nil {
+			return err
+		} else {
+			destination.Set(val)
+		}
+	} else if !policy.coerce() {
+		return util.ErrInvalidType(property, *destination, src)
+	} else if val, ok := src.(float64); ok {
+		return floatToBigInt(val, destination, property)
+	} else if val, ok := src.(float32); ok {
+		return floatToBigInt(float64(val), destination, property)
+	} else if val, ok := src.(string); ok {
+		if val, err := parseIntegral(val, property, BigIntMaxBits, *destination); err != nil {
+			return err
+		} else {
+			destination.Set(val)
+		}
+	} else {
+		return util.ErrInvalidType(property, *destination, src)
+	}
+	return nil
+}
+
+func ConvertoBigFloat(src, dst interface{}, property string) error {
+	return ConvertoBigFloatWith(src, dst, property, CoercionDefault)
+}
+
+func ConvertoBigFloatWith(src, dst interface{}, property string, policy CoercionPolicy) error {
+
+	destination, ok := dst.(*big.Float)
+	if !ok {
+		panic(util.ErrInvalidType(property, destination, dst).Error())
+	} else if destination.Prec() == 0 {
+		destination.SetPrec(BigFloatPrec)
+	}
+
+	if val, ok := src.(*big.Float); ok {
+		destination.Set(val)
+	} else if val, ok := src.(big.Float); ok {
+		destination.Set(&val)
+	} else if val, ok := src.(float64); ok {
+		return floatToBigFloat(val, destination, property)
+	} else if val, ok := src.(float32); ok {
+		return floatToBigFloat(float64(val), destination, property)
+	} else if val, ok := src.(json.Number); ok {
+		if err := checkExponent(string(val), property, *destination); err != nil {
+			return err
+		} else if _, ok := destination.SetString(string(val)); !ok {
+			return util.ErrInvalidNumber(property, val)
+		}
+	} else if !policy.coerce() {
+		return util.ErrInvalidType(property, *destination, src)
+	} else if val, ok := src.(*big.Int); ok {
+		destination.SetInt(val)
+	} else if val, ok := src.(*big.Rat); ok {
+		destination.SetRat(val)
+	} else if val, ok := integerOf(src); ok {
+		if val < 0 && isUnsigned(src) {
+			var u uint64
+			if err := ConvertoUint64(src, &u, property); err != nil {
+				return err
+			}
+			destination.SetUint64(u)
+		} else {
+			destination.SetInt64(val)
+		}
+	} else if val, ok := src.(string); ok {
+		if err := checkExponent(val, property, *destination); err != nil {
+			return err
+		} else if _, ok := destination.SetString(val); !ok {
+			return util.ErrInvalidNumber(property, val)
+		}
+	} else {
+		return util.ErrInvalidType(property, *destination, src)
+	}
+	return nil
+}
+
+// Convert into *big.Rat, which is the same kind as floats with CoercionStrict.
+func ConvertoBigRat(src, dst interface{}, property string) error {
+	return ConvertoBigRatWith(src, dst, property, CoercionDefault)
+}
+
+func ConvertoBigRatWith(src, dst interface{}, property string, policy CoercionPolicy) error {
+	if destination, ok := dst.(*big.Rat); !ok {
+		panic(util.ErrInvalidType(property, destination, dst).Error())
+	} else if val, ok := src.(*big.Rat); ok {
+		destination.Set(val)
+	} else if val, ok := src.(big.Rat); ok {
+		destination.Set(&val)
+	} else if val, ok := src.(float64); ok {
+		return floatToBigRat(val, destination, property)
+	} else if val, ok := src.(float32); ok {
+		return floatToBigRat(float64(val), destination, property)
+	} else if val, ok := src.(json.Number); ok {
+		if err := checkExponent(string(val), property, *destination); err != nil {
+			return err
+		} else if _, ok := destination.SetString(string(val)); !ok {
+			return util.ErrInvalidNumber(property, val)
+		}
+	} else if !policy.coerce() {
+		return util.ErrInvalidType(property, *destination, src)
+	} else if val, ok := src.(*big.Int); ok {
+		destination.SetInt(val)
+	} else if val, ok := integerOf(src); ok {
+		if val < 0 && isUnsigned(src) {
+			var u uint64
+			if err := ConvertoUint64(src, &u, property); err != nil {
+				return err
+			}
+			destination.SetUint64(u)
+		} else {
+			destination.SetInt64(val)
+		}
+	} else if val, ok := src.(string); ok {
+		if err := checkExponent(val, property, *destination); err != nil {
+			return err
+		} else if _, ok := destination.SetString(val); !ok {
+			return util.ErrInvalidNumber(property, val)
+		}
+	} else {
+		return util.ErrInvalidType(property, *destination, src)
+	}
+	return nil
+}
+
+func ConvertoJSONNumber(src, dst interface{}, property string) error {
+	return ConvertoJSONNumberWith(src, dst, property, CoercionDefault)
+}
+
+func ConvertoJSONNumberWith(src, dst interface{}, property string, policy CoercionPolicy) error {
+	if destination, ok := dst.(*json.Number); !ok {
+		panic(util.ErrInvalidType(property, destination, dst).Error())
+	} else if val, ok := src.(json.Number); ok {
+		*destination = val
+	} else if val, ok := integerOf(src); ok {
+		if val < 0 && isUnsigned(src) {
+			var u uint64
+			if err := ConvertoUint64(src, &u, property); err != nil {
+				return err
+			}
+			*destination = json.Number(strconv.FormatUint(u, 10))
+		} else {
+			*destination = json.Number(strconv.FormatInt(val, 10))
+		}
+	} else if val, ok := src.(float64); ok {
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return util.ErrInvalidNumber(property, val)
+		}
+		*destination = json.Number(strconv.FormatFloat(val, 'g', -1, 64))
+	} else if val, ok := src.(float32); ok {
+		if math.IsNaN(float64(val)) || math.IsInf(float64(val), 0) {
+			return util.ErrInvalidNumber(property, val)
+		}
+		*destination = json.Number(strconv.FormatFloat(float64(val), 'g', -1, 32))
+	} else if val, ok := src.(*big.Int); ok {
+		*destination = json.Number(val.String())
+	} else if val, ok := src.(*big.Float); ok {
+		if val.IsInf() {
+			return util.ErrInvalidNumber(property, val.String())
+		}
+		*destination = json.Number(val.Text('g', -1))
+	} else if val, ok := src.(string); ok && policy.coerce() {
+		if !jsonNumberMatches.MatchString(val) {
+			return util.ErrInvalidNumber(property, val)
+		}
+		*destination = json.Number(val)
+	} else {
+		return util.ErrInvalidType(property, *destination, src)
+	}
+	return nil
+}
+
+func floatToBigInt(val float64, destination *big.Int, property string) error {
+	if math.IsNaN(val) || math.IsInf(val, 0) || val != math.Trunc(val) {
+		return util.ErrNotIntegral(property, val)
+	}
+	big.NewFloat(val).Int(destination)
+	return nil
+}
+
+func floatToBigRat(val float64, destination *big.Rat, property string) error {
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return util.ErrInvalidNumber(property, val)
+	}
+	destination.SetFloat64(val)
+	return nil
+}
+
+func floatToBigFloat(val float64, destination *big.Float, property string) error {
+	if math.IsNaN(val) {
+		return util.ErrInvalidNumber(property, val)
+	}
+	destination.SetFloat64(val)
+	return nil
+}
+
+// Check the exponent of the number string, following e or E, or p or P of prefixed numbers, is at most BigMaxExp.
+// Strings without exponents, or with invalid exponents which fail to parse later, are passed.
+func checkExponent(str, property string, want interface{}) error {
+
+	marks := "eEpP"
+	if unsigned := strings.TrimLeft(str, "+-"); strings.HasPrefix(unsigned, "0x") || strings.HasPrefix(unsigned, "0X") {
+		marks = "pP"
+	}
+	at := strings.LastIndexAny(str, marks)
+	if at < 0 {
+		return nil
+	}
+
+	exp, err := strconv.ParseInt(strings.ReplaceAll(str[at+1:], "_", ""), 10, 64)
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		return util.ErrOutOfRange(property, want, str)
+	} else if err != nil {
+		return nil
+	} else if exp < -BigMaxExp || exp > BigMaxExp {
+		return util.ErrOutOfRange(property, want, str)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// standard/bytes.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+const (
+	// Uses bytes of the string as it is.
+	EncodingRaw = "raw"
+	// Decodes the string with standard or URL-safe base64, padded or not.
+	EncodingBase64 = "base64"
+	// Decodes the string with hexadecimal digits.
+	EncodingHex = "hex"
+)
+
+// Reports whether the encoding is supported by DecodeBytes.
+func ValidEncoding(encoding string) bool {
+	switch encoding {
+	case EncodingRaw, EncodingBase64, EncodingHex:
+		return true
+	default:
+		return false
+	}
+}
+
+// Decode bytes from the string with the encoding.
+// When the string is invalid, returned error shows the offset of the first invalid character.
+func DecodeBytes(str, encoding, property string) ([]byte, error) {
+
+	switch encoding {
+	case EncodingRaw:
+		return []byte(str), nil
+
+	case EncodingBase64:
+		enc := base64.StdEncoding
+		if strings.ContainsAny(str, "-_") {
+			enc = base64.URLEncoding
+		}
+		if !strings.HasSuffix(str, "=") && len(str)%4 != 0 {
+			enc = enc.WithPadding(base64.NoPadding)
+		}
+		if buf, err := enc.DecodeString(str); err != nil {
+			if offset, ok := err.(base64.CorruptInputError); ok {
+				return nil, util.ErrInvalidEncoding(property, encoding, int(offset))
+			}
+			return nil, util.ErrInvalidEncoding(property, encoding, len(str))
+		} else {
+			return buf, nil
+		}
+
+	case EncodingHex:
+		buf := make([]byte, len(str)/2)
+		for i := 0; i < len(str); i += 2 {
+			if i+1 >= len(str) {
+				return nil, util.ErrInvalidEncoding(property, encoding, len(str))
+			}
+			high, ok := fromHexChar(str[i])
+			if !ok {
+				return nil, util.ErrInvalidEncoding(property, encoding, i)
+			}
+			low, ok := fromHexChar(str[i+1])
+			if !ok {
+				return nil, util.ErrInvalidEncoding(property, encoding, i+1)
+			}
+			buf[i/2] = high<<4 | low
+		}
+		return buf, nil
+
+	default:
+		panic("not supported encoding: " + encoding)
+	}
+}
+
+// Converts to []byte from []byte, strings and []interface{} of integers.
+// Strings are decoded with EncodingRaw.
+func ConvertoBytes(src, dst interface{}, property string) error {
+	return ConvertoEncodedBytes(src, dst, property, EncodingRaw)
+}
+
+// Converts to []byte from []byte, strings and []interface{} of integers.
+// Strings are decoded with the encoding.
+func ConvertoEncodedBytes(src, dst interface{}, property, encoding string) error {
+	if destination, ok := dst.(*[]byte); !ok {
+		panic(util.ErrInvalidType(property, destination, dst).Error())
+	} else if val, ok := src.([]byte); ok {
+		*destination = append([]byte{}, val...)
+	} else if val, ok := src.(string); ok {
+		if buf, err := DecodeBytes(val, encoding, property); err != nil {
+			return err
+		} else {
+			*destination = buf
+		}
+	} else if val, ok := src.([]interface{}); ok {
+		buf := make([]byte, len(val))
+		for i, elem := range val {
+			if err := ConvertoByte(elem, &buf[i], property+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+		*destination = buf
+	} else {
+		return util.ErrInvalidType(property, *destination, src)
+	}
+	return nil
+}
+
+func fromHexChar(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	default:
+		return 0, false
+	}
+}
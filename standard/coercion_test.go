@@ -0,0 +1,298 @@
+// standard/coercion_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard_test
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/streamwest-1629/convertobject/standard"
+)
+
+func TestConvertoString(t *testing.T) {
+
+	for _, c := range []struct {
+		src  interface{}
+		want string
+	}{
+		{"str", "str"},
+		{int8(-8), "-8"},
+		{uint64(18446744073709551615), "18446744073709551615"},
+		{1.5, "1.5"},
+		{float32(0.1), "0.1"},
+		{true, "true"},
+		{[]byte("bytes"), "bytes"},
+		{net.IPv4(127, 0, 0, 1), "127.0.0.1"},
+	} {
+		dst := ""
+		if err := standard.ConvertoString(c.src, &dst, "prop"); err != nil {
+			t.Errorf("%#v: %s", c.src, err.Error())
+		} else if dst != c.want {
+			t.Errorf("%#v: want %q, has %q", c.src, c.want, dst)
+		}
+	}
+}
+
+func TestConvertoBool(t *testing.T) {
+
+	for _, c := range []struct {
+		src  interface{}
+		want bool
+	}{
+		{true, true},
+		{"yes", true},
+		{"Off", false},
+		{"1", true},
+		{uint8(0), false},
+		{int64(1), true},
+	} {
+		dst := !c.want
+		if err := standard.ConvertoBool(c.src, &dst, "prop"); err != nil {
+			t.Errorf("%#v: %s", c.src, err.Error())
+		} else if dst != c.want {
+			t.Errorf("%#v: want %v, has %v", c.src, c.want, dst)
+		}
+	}
+
+	for _, src := range []interface{}{2, "maybe", 1.0} {
+		dst := false
+		if err := standard.ConvertoBool(src, &dst, "prop"); err == nil {
+			t.Errorf("%#v: want error", src)
+		}
+	}
+}
+
+func TestCoercionStrict(t *testing.T) {
+
+	standard.Coercion = standard.CoercionStrict
+	defer func() { standard.Coercion = standard.CoercionLoose }()
+
+	str, boolean, integer, float := "", false, int64(0), float64(0)
+	if err := standard.ConvertoString(1, &str, "prop"); err == nil {
+		t.Error("string from int: want error")
+	}
+	if err := standard.ConvertoBool("true", &boolean, "prop"); err == nil {
+		t.Error("bool from string: want error")
+	}
+	if err := standard.ConvertoInt64("1", &integer, "prop"); err == nil {
+		t.Error("int64 from string: want error")
+	}
+	if err := standard.ConvertoFloat64(1, &float, "prop"); err == nil {
+		t.Error("float64 from int: want error")
+	}
+	if err := standard.ConvertoInt64(int8(1), &integer, "prop"); err != nil || integer != 1 {
+		t.Errorf("int64 from int8: %v", err)
+	}
+
+	i, f, r := new(big.Int), new(big.Float), new(big.Rat)
+	for name, err := range map[string]error{
+		"big.Int from float64":    standard.ConvertoBigInt(1.0, i, "prop"),
+		"big.Float from int":      standard.ConvertoBigFloat(1, f, "prop"),
+		"big.Float from *big.Int": standard.ConvertoBigFloat(big.NewInt(1), f, "prop"),
+		"big.Rat from int":        standard.ConvertoBigRat(1, r, "prop"),
+		"big.Rat from string":     standard.ConvertoBigRat("1/3", r, "prop"),
+	} {
+		if err == nil {
+			t.Errorf("%s: want error", name)
+		}
+	}
+	if err := standard.ConvertoBigInt(1.0, i, "prop"); err == nil || err.Error() != "prop is invalid type (want: math/big.Int, has: float64)" {
+		t.Errorf("big.Int from float64: want error of big.Int, has %v", err)
+	}
+	if err := standard.ConvertoBigFloat(1.5, f, "prop"); err != nil {
+		t.Errorf("big.Float from float64: %v", err)
+	}
+	if err := standard.ConvertoBigRat(0.5, r, "prop"); err != nil || r.String() != "1/2" {
+		t.Errorf("big.Rat from float64: %v", err)
+	}
+}
+
+func TestCoercionPerCall(t *testing.T) {
+
+	integer, str := int64(0), ""
+	if err := standard.ConvertoInt64With("1", &integer, "prop", standard.CoercionStrict); err == nil {
+		t.Error("int64 from string with strict: want error")
+	}
+	if err := standard.ConvertoInt64With("1", &integer, "prop", standard.CoercionDefault); err != nil || integer != 1 {
+		t.Errorf("int64 from string with default: %v", err)
+	}
+
+	standard.Coercion = standard.CoercionStrict
+	defer func() { standard.Coercion = standard.CoercionLoose }()
+	if err := standard.ConvertoStringWith(1, &str, "prop", standard.CoercionLoose); err != nil || str != "1" {
+		t.Errorf("string from int with loose: %v", err)
+	}
+	if err := standard.ConvertoString(1, &str, "prop"); err == nil {
+		t.Error("string from int with default strict: want error")
+	}
+}
+
+func TestConvertoInt64(t *testing.T) {
+
+	for _, c := range []struct {
+		src  interface{}
+		want int64
+	}{
+		{float64(42), 42},
+		{float32(-3), -3},
+		{json.Number("12"), 12},
+		{json.Number("1e3"), 1000},
+		{"1_000", 1000},
+		{"0x10", 16},
+		{"2.5e1", 25},
+		{"-9223372036854775808", math.MinInt64},
+		{"0x1p4", 16},
+		{"1." + strings.Repeat("0", 400) + "e2", 100},
+	} {
+		dst := int64(0)
+		if err := standard.ConvertoInt64(c.src, &dst, "prop"); err != nil {
+			t.Errorf("%#v: %s", c.src, err.Error())
+		} else if dst != c.want {
+			t.Errorf("%#v: want %d, has %d", c.src, c.want, dst)
+		}
+	}
+
+	for _, src := range []interface{}{1.5, math.Inf(1), 1e19, "1.5", "9223372036854775808", "abc", json.Number("0.1"), true, "1e600000000", json.Number("-1e600000000"),
+		"1." + strings.Repeat("0", 400) + "1", json.Number("2." + strings.Repeat("0", 400) + "1e2"), "1e-1000000000"} {
+		dst := int64(0)
+		if err := standard.ConvertoInt64(src, &dst, "prop"); err == nil {
+			t.Errorf("%#v: want error", src)
+		}
+	}
+}
+
+func TestConvertoNarrowInteger(t *testing.T) {
+
+	i8, u8, u64 := int8(0), uint8(0), uint64(0)
+	if err := standard.ConvertoInt8(128, &i8, "prop"); err == nil {
+		t.Error("int8 from 128: want error")
+	}
+	if err := standard.ConvertoUint8(-1, &u8, "prop"); err == nil {
+		t.Error("uint8 from -1: want error")
+	}
+	if err := standard.ConvertoUint64(1.8446744073709552e19, &u64, "prop"); err == nil {
+		t.Error("uint64 from 2^64: want error")
+	}
+	if err := standard.ConvertoUint64("18446744073709551615", &u64, "prop"); err != nil || u64 != math.MaxUint64 {
+		t.Errorf("uint64 from max: %v", err)
+	}
+}
+
+func TestDecodeBytes(t *testing.T) {
+
+	for _, c := range []struct {
+		src, encoding, want string
+	}{
+		{"raw", standard.EncodingRaw, "raw"},
+		{"aGVsbG8=", standard.EncodingBase64, "hello"},
+		{"aGVsbG8", standard.EncodingBase64, "hello"},
+		{"-_8", standard.EncodingBase64, "\xfb\xff"},
+		{"68656C6c6f", standard.EncodingHex, "hello"},
+	} {
+		if buf, err := standard.DecodeBytes(c.src, c.encoding, "prop"); err != nil {
+			t.Errorf("%q: %s", c.src, err.Error())
+		} else if string(buf) != c.want {
+			t.Errorf("%q: want %q, has %q", c.src, c.want, buf)
+		}
+	}
+
+	for _, c := range []struct {
+		src, encoding, want string
+	}{
+		{"aGV*bG8=", standard.EncodingBase64, "prop is invalid base64 string (at offset 3)"},
+		{"68x5", standard.EncodingHex, "prop is invalid hex string (at offset 2)"},
+		{"686", standard.EncodingHex, "prop is invalid hex string (at offset 3)"},
+	} {
+		if _, err := standard.DecodeBytes(c.src, c.encoding, "prop"); err == nil {
+			t.Errorf("%q: want error", c.src)
+		} else if err.Error() != c.want {
+			t.Errorf("%q: want %q, has %q", c.src, c.want, err.Error())
+		}
+	}
+}
+
+func TestConvertoBig(t *testing.T) {
+
+	i, f, r, n := new(big.Int), new(big.Float), new(big.Rat), json.Number("")
+
+	if err := standard.ConvertoBigInt("123456789012345678901234567890", i, "prop"); err != nil || i.String() != "123456789012345678901234567890" {
+		t.Errorf("big.Int from string: %v, %s", err, i.String())
+	}
+	if err := standard.ConvertoBigInt(uint64(math.MaxUint64), i, "prop"); err != nil || i.String() != "18446744073709551615" {
+		t.Errorf("big.Int from uint64: %v, %s", err, i.String())
+	}
+	if err := standard.ConvertoBigInt(json.Number("1.5"), i, "prop"); err == nil {
+		t.Error("big.Int from 1.5: want error")
+	}
+	if err := standard.ConvertoBigInt("1e100", i, "prop"); err != nil || i.String() != "1"+strings.Repeat("0", 100) {
+		t.Errorf("big.Int from 1e100: %v, %s", err, i.String())
+	}
+	if err := standard.ConvertoBigInt("1e600000000", i, "prop"); err == nil {
+		t.Error("big.Int from 1e600000000: want error")
+	}
+	if err := standard.ConvertoBigFloat(json.Number("0.1"), f, "prop"); err != nil || f.Prec() != standard.BigFloatPrec {
+		t.Errorf("big.Float from json.Number: %v, prec %d", err, f.Prec())
+	}
+	if err := standard.ConvertoBigRat("1/3", r, "prop"); err != nil || r.String() != "1/3" {
+		t.Errorf("big.Rat from string: %v, %s", err, r.String())
+	}
+	if err := standard.ConvertoBigRat(json.Number("0.25"), r, "prop"); err != nil || r.String() != "1/4" {
+		t.Errorf("big.Rat from json.Number: %v, %s", err, r.String())
+	}
+	for _, src := range []interface{}{"1e1000000000", json.Number("1e-1000000000"), "0x1p-99999999999", "1e99999999999999999999"} {
+		if err := standard.ConvertoBigRat(src, r, "prop"); err == nil {
+			t.Errorf("big.Rat from %v: want error", src)
+		}
+		if err := standard.ConvertoBigFloat(src, f, "prop"); err == nil {
+			t.Errorf("big.Float from %v: want error", src)
+		}
+	}
+	if err := standard.ConvertoBigRat("1e-3", r, "prop"); err != nil || r.String() != "1/1000" {
+		t.Errorf("big.Rat from 1e-3: %v, %s", err, r.String())
+	}
+	if err := standard.ConvertoBigFloat("0x1p-4", f, "prop"); err != nil || f.String() != "0.0625" {
+		t.Errorf("big.Float from 0x1p-4: %v, %s", err, f.String())
+	}
+	if err := standard.ConvertoJSONNumber(1.5, &n, "prop"); err != nil || n != "1.5" {
+		t.Errorf("json.Number from float64: %v, %s", err, n)
+	}
+	if err := standard.ConvertoJSONNumber("1.2.3", &n, "prop"); err == nil {
+		t.Error("json.Number from invalid string: want error")
+	}
+
+	float := float64(0)
+	if err := standard.ConvertoFloat64(json.Number("2.5"), &float, "prop"); err != nil || float != 2.5 {
+		t.Errorf("float64 from json.Number: %v, %v", err, float)
+	}
+}
+
+func TestConvertoTime(t *testing.T) {
+
+	dst := time.Time{}
+	if err := standard.ConvertoTime("1979-05-27 07:32:00Z", &dst, "prop"); err != nil {
+		t.Error(err.Error())
+	} else if want := time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC); !dst.Equal(want) {
+		t.Errorf("want %s, has %s", want, dst)
+	}
+	if err := standard.ConvertoTime(1, &dst, "prop"); err == nil || err.Error() != "prop is invalid type (want: time.Time, has: int)" {
+		t.Errorf("time.Time from int: want error of time.Time, has %v", err)
+	}
+}
@@ -16,13 +16,19 @@
 package standard
 
 import (
+	"encoding/json"
 	"math"
+	"math/big"
 	"strconv"
 
 	"github.com/streamwest-1629/convertobject/util"
 )
 
 func ConvertoInt64(src, dst interface{}, property string) error {
+	return ConvertoInt64With(src, dst, property, CoercionDefault)
+}
+
+func ConvertoInt64With(src, dst interface{}, property string, policy CoercionPolicy) error {
 	if destination, ok := dst.(*int64); !ok {
 		panic(util.ErrInvalidType(property, destination, dst).Error())
 	} else if val, ok := src.(int64); ok {
@@ -37,7 +43,7 @@ func ConvertoInt64(src, dst interface{}, property string) error {
 		*destination = int64(val)
 	} else if val, ok := src.(uint64); ok {
 		if val > math.MaxInt64 {
-			return util.ErrInvalidType(property, *destination, val)
+			return util.ErrOutOfRange(property, *destination, val)
 		}
 		*destination = int64(val)
 	} else if val, ok := src.(uint32); ok {
@@ -50,45 +56,69 @@ func ConvertoInt64(src, dst interface{}, property string) error {
 		*destination = int64(val)
 	} else if val, ok := src.(byte); ok {
 		*destination = int64(val)
+	} else if val, ok := src.(json.Number); ok {
+		if val, err := parseInt64(string(val), property); err != nil {
+			return err
+		} else {
+			*destination = val
+		}
+	} else if !policy.coerce() {
+		return util.ErrInvalidType(property, *destination, src)
+	} else if val, ok := src.(float64); ok {
+		if val, err := floatToInt64(val, property); err != nil {
+			return err
+		} else {
+			*destination = val
+		}
+	} else if val, ok := src.(float32); ok {
+		if val, err := floatToInt64(float64(val), property); err != nil {
+			return err
+		} else {
+			*destination = val
+		}
 	} else if val, ok := src.(string); ok {
-		if val, err := strconv.ParseInt(val, 0, 64); err != nil {
+		if val, err := parseInt64(val, property); err != nil {
 			return err
 		} else {
 			*destination = val
 		}
 	} else {
-		util.ErrInvalidType(property, *destination, src)
+		return util.ErrInvalidType(property, *destination, src)
 	}
 	return nil
 }
 
 func ConvertoUint64(src, dst interface{}, property string) error {
+	return ConvertoUint64With(src, dst, property, CoercionDefault)
+}
+
+func ConvertoUint64With(src, dst interface{}, property string, policy CoercionPolicy) error {
 
 	if destination, ok := dst.(*uint64); !ok {
 		panic(util.ErrInvalidType(property, destination, dst).Error())
 	} else if val, ok := src.(int64); ok {
 		if val < 0 {
-			return util.ErrInvalidType(property, *destination, val)
+			return util.ErrOutOfRange(property, *destination, val)
 		}
 		*destination = uint64(val)
 	} else if val, ok := src.(int32); ok {
 		if val < 0 {
-			return util.ErrInvalidType(property, *destination, val)
+			return util.ErrOutOfRange(property, *destination, val)
 		}
 		*destination = uint64(val)
 	} else if val, ok := src.(int16); ok {
 		if val < 0 {
-			return util.ErrInvalidType(property, *destination, val)
+			return util.ErrOutOfRange(property, *destination, val)
 		}
 		*destination = uint64(val)
 	} else if val, ok := src.(int8); ok {
 		if val < 0 {
-			return util.ErrInvalidType(property, *destination, val)
+			return util.ErrOutOfRange(property, *destination, val)
 		}
 		*destination = uint64(val)
 	} else if val, ok := src.(int); ok {
 		if val < 0 {
-			return util.ErrInvalidType(property, *destination, val)
+			return util.ErrOutOfRange(property, *destination, val)
 		}
 		*destination = uint64(val)
 	} else if val, ok := src.(uint64); ok {
@@ -103,57 +133,101 @@ func ConvertoUint64(src, dst interface{}, property string) error {
 		*destination = uint64(val)
 	} else if val, ok := src.(byte); ok {
 		*destination = uint64(val)
+	} else if val, ok := src.(json.Number); ok {
+		if val, err := parseUint64(string(val), property); err != nil {
+			return err
+		} else {
+			*destination = val
+		}
+	} else if !policy.coerce() {
+		return util.ErrInvalidType(property, *destination, src)
+	} else if val, ok := src.(float64); ok {
+		if val, err := floatToUint64(val, property); err != nil {
+			return err
+		} else {
+			*destination = val
+		}
+	} else if val, ok := src.(float32); ok {
+		if val, err := floatToUint64(float64(val), property); err != nil {
+			return err
+		} else {
+			*destination = val
+		}
 	} else if val, ok := src.(string); ok {
-		if val, err := strconv.ParseUint(val, 0, 64); err != nil {
+		if val, err := parseUint64(val, property); err != nil {
 			return err
 		} else {
 			*destination = val
 		}
 	} else {
-		util.ErrInvalidType(property, *destination, src)
+		return util.ErrInvalidType(property, *destination, src)
 	}
 	return nil
 }
 
 func ConvertoInt32(src, dst interface{}, property string) error {
+	return ConvertoInt32With(src, dst, property, CoercionDefault)
+}
+
+func ConvertoInt32With(src, dst interface{}, property string, policy CoercionPolicy) error {
 	buf := int64(0)
 	if destination, ok := dst.(*int32); !ok {
 		panic(util.ErrInvalidType(property, destination, dst).Error())
-	} else if err := ConvertoInt64(src, &buf, property); err != nil {
+	} else if err := ConvertoInt64With(src, &buf, property, policy); err != nil {
 		return err
+	} else if buf < math.MinInt32 || buf > math.MaxInt32 {
+		return util.ErrOutOfRange(property, *destination, buf)
 	} else {
 		*destination = int32(buf)
 		return nil
 	}
 }
 func ConvertoInt16(src, dst interface{}, property string) error {
+	return ConvertoInt16With(src, dst, property, CoercionDefault)
+}
+
+func ConvertoInt16With(src, dst interface{}, property string, policy CoercionPolicy) error {
 	buf := int64(0)
 	if destination, ok := dst.(*int16); !ok {
 		panic(util.ErrInvalidType(property, destination, dst).Error())
-	} else if err := ConvertoInt64(src, &buf, property); err != nil {
+	} else if err := ConvertoInt64With(src, &buf, property, policy); err != nil {
 		return err
+	} else if buf < math.MinInt16 || buf > math.MaxInt16 {
+		return util.ErrOutOfRange(property, *destination, buf)
 	} else {
 		*destination = int16(buf)
 		return nil
 	}
 }
 func ConvertoInt8(src, dst interface{}, property string) error {
+	return ConvertoInt8With(src, dst, property, CoercionDefault)
+}
+
+func ConvertoInt8With(src, dst interface{}, property string, policy CoercionPolicy) error {
 	buf := int64(0)
 	if destination, ok := dst.(*int8); !ok {
 		panic(util.ErrInvalidType(property, destination, dst).Error())
-	} else if err := ConvertoInt64(src, &buf, property); err != nil {
+	} else if err := ConvertoInt64With(src, &buf, property, policy); err != nil {
 		return err
+	} else if buf < math.MinInt8 || buf > math.MaxInt8 {
+		return util.ErrOutOfRange(property, *destination, buf)
 	} else {
 		*destination = int8(buf)
 		return nil
 	}
 }
 func ConvertoInt(src, dst interface{}, property string) error {
+	return ConvertoIntWith(src, dst, property, CoercionDefault)
+}
+
+func ConvertoIntWith(src, dst interface{}, property string, policy CoercionPolicy) error {
 	buf := int64(0)
 	if destination, ok := dst.(*int); !ok {
 		panic(util.ErrInvalidType(property, destination, dst).Error())
-	} else if err := ConvertoInt64(src, &buf, property); err != nil {
+	} else if err := ConvertoInt64With(src, &buf, property, policy); err != nil {
 		return err
+	} else if int64(int(buf)) != buf {
+		return util.ErrOutOfRange(property, *destination, buf)
 	} else {
 		*destination = int(buf)
 		return nil
@@ -161,11 +235,17 @@ func ConvertoInt(src, dst interface{}, property string) error {
 }
 
 func ConvertoUint32(src, dst interface{}, property string) error {
+	return ConvertoUint32With(src, dst, property, CoercionDefault)
+}
+
+func ConvertoUint32With(src, dst interface{}, property string, policy CoercionPolicy) error {
 	buf := uint64(0)
 	if destination, ok := dst.(*uint32); !ok {
 		panic(util.ErrInvalidType(property, destination, dst).Error())
-	} else if err := ConvertoUint64(src, &buf, property); err != nil {
+	} else if err := ConvertoUint64With(src, &buf, property, policy); err != nil {
 		return err
+	} else if buf > math.MaxUint32 {
+		return util.ErrOutOfRange(property, *destination, buf)
 	} else {
 		*destination = uint32(buf)
 		return nil
@@ -173,11 +253,17 @@ func ConvertoUint32(src, dst interface{}, property string) error {
 }
 
 func ConvertoUint16(src, dst interface{}, property string) error {
+	return ConvertoUint16With(src, dst, property, CoercionDefault)
+}
+
+func ConvertoUint16With(src, dst interface{}, property string, policy CoercionPolicy) error {
 	buf := uint64(0)
 	if destination, ok := dst.(*uint16); !ok {
 		panic(util.ErrInvalidType(property, destination, dst).Error())
-	} else if err := ConvertoUint64(src, &buf, property); err != nil {
+	} else if err := ConvertoUint64With(src, &buf, property, policy); err != nil {
 		return err
+	} else if buf > math.MaxUint16 {
+		return util.ErrOutOfRange(property, *destination, buf)
 	} else {
 		*destination = uint16(buf)
 		return nil
@@ -185,11 +271,17 @@ func ConvertoUint16(src, dst interface{}, property string) error {
 }
 
 func ConvertoUint8(src, dst interface{}, property string) error {
+	return ConvertoUint8With(src, dst, property, CoercionDefault)
+}
+
+func ConvertoUint8With(src, dst interface{}, property string, policy CoercionPolicy) error {
 	buf := uint64(0)
 	if destination, ok := dst.(*uint8); !ok {
 		panic(util.ErrInvalidType(property, destination, dst).Error())
-	} else if err := ConvertoUint64(src, &buf, property); err != nil {
+	} else if err := ConvertoUint64With(src, &buf, property, policy); err != nil {
 		return err
+	} else if buf > math.MaxUint8 {
+		return util.ErrOutOfRange(property, *destination, buf)
 	} else {
 		*destination = uint8(buf)
 		return nil
@@ -197,11 +289,17 @@ func ConvertoUint8(src, dst interface{}, property string) error {
 }
 
 func ConvertoUint(src, dst interface{}, property string) error {
+	return ConvertoUintWith(src, dst, property, CoercionDefault)
+}
+
+func ConvertoUintWith(src, dst interface{}, property string, policy CoercionPolicy) error {
 	buf := uint64(0)
 	if destination, ok := dst.(*uint); !ok {
 		panic(util.ErrInvalidType(property, destination, dst).Error())
-	} else if err := ConvertoUint64(src, &buf, property); err != nil {
+	} else if err := ConvertoUint64With(src, &buf, property, policy); err != nil {
 		return err
+	} else if uint64(uint(buf)) != buf {
+		return util.ErrOutOfRange(property, *destination, buf)
 	} else {
 		*destination = uint(buf)
 		return nil
@@ -209,13 +307,95 @@ func ConvertoUint(src, dst interface{}, property string) error {
 }
 
 func ConvertoByte(src, dst interface{}, property string) error {
+	return ConvertoByteWith(src, dst, property, CoercionDefault)
+}
+
+func ConvertoByteWith(src, dst interface{}, property string, policy CoercionPolicy) error {
 	buf := uint64(0)
 	if destination, ok := dst.(*byte); !ok {
 		panic(util.ErrInvalidType(property, destination, dst).Error())
-	} else if err := ConvertoUint64(src, &buf, property); err != nil {
+	} else if err := ConvertoUint64With(src, &buf, property, policy); err != nil {
 		return err
+	} else if buf > math.MaxUint8 {
+		return util.ErrOutOfRange(property, *destination, buf)
 	} else {
 		*destination = byte(buf)
 		return nil
 	}
 }
+
+// Parse signed integer from the string.
+// In addition to strconv.ParseInt, allows exponent forms such as "1e3" when the value is integral.
+func parseInt64(str, property string) (int64, error) {
+	if val, err := strconv.ParseInt(str, 0, 64); err == nil {
+		return val, nil
+	} else if num, err := parseIntegral(str, property, 64, int64(0)); err != nil {
+		return 0, err
+	} else if !num.IsInt64() {
+		return 0, util.ErrOutOfRange(property, int64(0), str)
+	} else {
+		return num.Int64(), nil
+	}
+}
+
+// Parse unsigned integer from the string.
+// In addition to strconv.ParseUint, allows exponent forms such as "1e3" when the value is integral.
+func parseUint64(str, property string) (uint64, error) {
+	if val, err := strconv.ParseUint(str, 0, 64); err == nil {
+		return val, nil
+	} else if num, err := parseIntegral(str, property, 64, uint64(0)); err != nil {
+		return 0, err
+	} else if !num.IsUint64() {
+		return 0, util.ErrOutOfRange(property, uint64(0), str)
+	} else {
+		return num.Uint64(), nil
+	}
+}
+
+// Parse the string as decimal or prefixed number exactly, and returns it when it is integral.
+// Strings with long fractions, rounded to integers at the precision, are not integral.
+// Numbers whose integer part is longer than bits are out of range of want's type,
+// which is checked before the integer is built, so that short strings such as "1e600000000" are rejected cheaply.
+func parseIntegral(str, property string, bits int, want interface{}) (*big.Int, error) {
+
+	prec := uint(1024)
+	if bits+64 > int(prec) {
+		prec = uint(bits + 64)
+	}
+
+	// exponents are limited before the exact check, so that numbers underflowing to zero such as "1e-1000000000" are not expanded
+	if err := checkExponent(str, property, want); err != nil {
+		return nil, err
+	} else if num, _, err := big.ParseFloat(str, 0, prec, big.ToNearestEven); err != nil {
+		return nil, util.ErrInvalidNumber(property, str)
+	} else if num.MantExp(nil) > bits {
+		return nil, util.ErrOutOfRange(property, want, str)
+	} else if !num.IsInt() {
+		return nil, util.ErrNotIntegral(property, str)
+	} else if exact, ok := new(big.Rat).SetString(str); !ok || !exact.IsInt() {
+		// fractions longer than prec are rounded by ParseFloat, so that the string is checked exactly
+		return nil, util.ErrNotIntegral(property, str)
+	} else {
+		return new(big.Int).Set(exact.Num()), nil
+	}
+}
+
+func floatToInt64(val float64, property string) (int64, error) {
+	if math.IsNaN(val) || math.IsInf(val, 0) || val != math.Trunc(val) {
+		return 0, util.ErrNotIntegral(property, val)
+	} else if val < math.MinInt64 || val >= 1<<63 {
+		return 0, util.ErrOutOfRange(property, int64(0), val)
+	} else {
+		return int64(val), nil
+	}
+}
+
+func floatToUint64(val float64, property string) (uint64, error) {
+	if math.IsNaN(val) || math.IsInf(val, 0) || val != math.Trunc(val) {
+		return 0, util.ErrNotIntegral(property, val)
+	} else if val < 0 || val >= 1<<64 {
+		return 0, util.ErrOutOfRange(property, uint64(0), val)
+	} else {
+		return uint64(val), nil
+	}
+}
@@ -56,6 +56,10 @@ func ConvertoInterfaceKeyInterfaceMap(src, dst interface{}, property string) err
 }
 
 func ConvertoStringKeyInterfaceMap(src, dst interface{}, property string) error {
+	return ConvertoStringKeyInterfaceMapWith(src, dst, property, CoercionDefault)
+}
+
+func ConvertoStringKeyInterfaceMapWith(src, dst interface{}, property string, policy CoercionPolicy) error {
 
 	var destination *map[string]interface{}
 
@@ -81,7 +85,7 @@ func ConvertoStringKeyInterfaceMap(src, dst interface{}, property string) error
 	} else if mapped, ok := src.(map[interface{}]interface{}); ok {
 		for key, val := range mapped {
 			keyStr := ""
-			if err := ConvertoString(key, &keyStr, property+".(key)"); err != nil {
+			if err := ConvertoStringWith(key, &keyStr, property+".(key)", policy); err != nil {
 				return err
 			}
 			(*destination)[keyStr] = val
@@ -93,6 +97,10 @@ func ConvertoStringKeyInterfaceMap(src, dst interface{}, property string) error
 }
 
 func ConvertoStringKeyStringMap(src, dst interface{}, property string) error {
+	return ConvertoStringKeyStringMapWith(src, dst, property, CoercionDefault)
+}
+
+func ConvertoStringKeyStringMapWith(src, dst interface{}, property string, policy CoercionPolicy) error {
 
 	var destination *map[string]string
 
@@ -110,7 +118,7 @@ func ConvertoStringKeyStringMap(src, dst interface{}, property string) error {
 	} else if mapped, ok := src.(map[string]interface{}); ok {
 		for key, val := range mapped {
 			valStr := ""
-			if err := ConvertoString(val, &valStr, property+".(key)"); err != nil {
+			if err := ConvertoStringWith(val, &valStr, property+".(key)", policy); err != nil {
 				return err
 			}
 			(*destination)[key] = valStr
@@ -118,9 +126,9 @@ func ConvertoStringKeyStringMap(src, dst interface{}, property string) error {
 	} else if mapped, ok := src.(map[interface{}]interface{}); ok {
 		for key, val := range mapped {
 			keyStr, valStr := "", ""
-			if err := ConvertoString(key, &keyStr, property+".(key)"); err != nil {
+			if err := ConvertoStringWith(key, &keyStr, property+".(key)", policy); err != nil {
 				return err
-			} else if err := ConvertoString(val, &valStr, property+".(value)"); err != nil {
+			} else if err := ConvertoStringWith(val, &valStr, property+".(value)", policy); err != nil {
 				return err
 			}
 			(*destination)[keyStr] = valStr
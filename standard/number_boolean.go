@@ -16,18 +16,34 @@
 package standard
 
 import (
+	"encoding/json"
+	"math"
+	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/streamwest-1629/convertobject/util"
 )
 
 func ConvertoFloat64(src, dst interface{}, property string) error {
+	return ConvertoFloat64With(src, dst, property, CoercionDefault)
+}
+
+func ConvertoFloat64With(src, dst interface{}, property string, policy CoercionPolicy) error {
 	if destination, ok := dst.(*float64); !ok {
 		panic(util.ErrInvalidType(property, destination, dst).Error())
 	} else if val, ok := src.(float64); ok {
 		*destination = float64(val)
 	} else if val, ok := src.(float32); ok {
 		*destination = float64(val)
+	} else if num, ok := src.(json.Number); ok {
+		if val, err := strconv.ParseFloat(string(num), 64); err != nil {
+			return util.ErrInvalidNumber(property, num)
+		} else {
+			*destination = val
+		}
+	} else if !policy.coerce() {
+		return util.ErrInvalidType(property, *destination, src)
 	} else if val, ok := src.(int64); ok {
 		*destination = float64(val)
 	} else if val, ok := src.(int32); ok {
@@ -64,11 +80,15 @@ func ConvertoFloat64(src, dst interface{}, property string) error {
 }
 
 func ConvertoFloat32(src, dst interface{}, property string) error {
+	return ConvertoFloat32With(src, dst, property, CoercionDefault)
+}
+
+func ConvertoFloat32With(src, dst interface{}, property string, policy CoercionPolicy) error {
 	buf := float64(0)
 
 	if destination, ok := dst.(*float32); !ok {
 		panic(util.ErrInvalidType(property, destination, dst).Error())
-	} else if err := ConvertoFloat64(src, &buf, property); err != nil {
+	} else if err := ConvertoFloat64With(src, &buf, property, policy); err != nil {
 		return err
 	} else {
 		*destination = float32(buf)
@@ -77,18 +97,67 @@ func ConvertoFloat32(src, dst interface{}, property string) error {
 }
 
 func ConvertoBool(src, dst interface{}, property string) error {
+	return ConvertoBoolWith(src, dst, property, CoercionDefault)
+}
+
+func ConvertoBoolWith(src, dst interface{}, property string, policy CoercionPolicy) error {
 	if destination, ok := dst.(*bool); !ok {
 		panic(util.ErrInvalidType(property, destination, dst).Error())
 	} else if val, ok := src.(bool); ok {
 		*destination = val
+	} else if !policy.coerce() {
+		return util.ErrInvalidType(property, *destination, src)
 	} else if val, ok := src.(string); ok {
-		if val, err := strconv.ParseBool(val); err != nil {
+		if val, err := parseBoolWord(val); err != nil {
 			return err
 		} else {
 			*destination = val
 		}
+	} else if val, ok := integerOf(src); ok {
+		if val != 0 && val != 1 {
+			return util.ErrInvalidType(property, *destination, src)
+		}
+		*destination = val == 1
 	} else {
 		return util.ErrInvalidType(property, *destination, src)
 	}
 	return nil
 }
+
+// Parse boolean from the string, which allows words yes/no, on/off and y/n in addition to strconv.ParseBool.
+func parseBoolWord(str string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(str)) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	default:
+		return strconv.ParseBool(str)
+	}
+}
+
+// Get integer value from integer kinds.
+// The value of unsigned integers beyond math.MaxInt64 is reported as -1, check it with isUnsigned.
+func integerOf(src interface{}) (int64, bool) {
+	switch val := reflect.ValueOf(src); val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return val.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := val.Uint(); u > math.MaxInt64 {
+			return -1, true
+		} else {
+			return int64(u), true
+		}
+	default:
+		return 0, false
+	}
+}
+
+func isUnsigned(src interface{}) bool {
+	switch reflect.ValueOf(src).Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,58 @@
+// standard/policy.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+// Defines which source kinds are accepted by the standard converters.
+// Converto* functions use Coercion, and Converto*With functions use the policy given per call.
+type CoercionPolicy int
+
+const (
+	// Follows the default policy, Coercion.
+	CoercionDefault CoercionPolicy = iota
+	// Converts across kinds: numbers and booleans from strings, strings from numbers, booleans and bytes,
+	// and booleans from integers 0 and 1.
+	CoercionLoose
+	// Converts only within the same kind: integers from integers, floats from floats,
+	// strings from strings and booleans from booleans.
+	CoercionStrict
+)
+
+var (
+	// Default coercion policy of the standard converters, used when no policy is given per call.
+	// Set it before conversions start, such as in init(), and give policies per call to convert with others.
+	Coercion = CoercionLoose
+)
+
+func (p CoercionPolicy) String() string {
+	switch p {
+	case CoercionDefault:
+		return "default"
+	case CoercionLoose:
+		return "loose"
+	case CoercionStrict:
+		return "strict"
+	default:
+		return "unknown"
+	}
+}
+
+// Reports whether the policy converts across kinds.
+func (p CoercionPolicy) coerce() bool {
+	if p == CoercionDefault {
+		p = Coercion
+	}
+	return p != CoercionStrict
+}
@@ -16,20 +16,53 @@
 package standard
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/streamwest-1629/convertobject/util"
 )
 
 func ConvertoString(src, dst interface{}, property string) error {
+	return ConvertoStringWith(src, dst, property, CoercionDefault)
+}
+
+func ConvertoStringWith(src, dst interface{}, property string, policy CoercionPolicy) error {
 	if destination, ok := dst.(*string); !ok {
 		panic(util.ErrInvalidType(property, destination, dst))
 	} else if val, ok := src.(string); ok {
 		*destination = val
+	} else if !policy.coerce() {
+		return util.ErrInvalidType(property, *destination, src)
 	} else if val, ok := src.(int); ok {
 		*destination = strconv.Itoa(val)
 	} else if val, ok := src.(int64); ok {
 		*destination = strconv.FormatInt(val, 10)
+	} else if val, ok := src.(int32); ok {
+		*destination = strconv.FormatInt(int64(val), 10)
+	} else if val, ok := src.(int16); ok {
+		*destination = strconv.FormatInt(int64(val), 10)
+	} else if val, ok := src.(int8); ok {
+		*destination = strconv.FormatInt(int64(val), 10)
+	} else if val, ok := src.(uint64); ok {
+		*destination = strconv.FormatUint(val, 10)
+	} else if val, ok := src.(uint32); ok {
+		*destination = strconv.FormatUint(uint64(val), 10)
+	} else if val, ok := src.(uint16); ok {
+		*destination = strconv.FormatUint(uint64(val), 10)
+	} else if val, ok := src.(uint8); ok {
+		*destination = strconv.FormatUint(uint64(val), 10)
+	} else if val, ok := src.(uint); ok {
+		*destination = strconv.FormatUint(uint64(val), 10)
+	} else if val, ok := src.(float64); ok {
+		*destination = strconv.FormatFloat(val, 'g', -1, 64)
+	} else if val, ok := src.(float32); ok {
+		*destination = strconv.FormatFloat(float64(val), 'g', -1, 32)
+	} else if val, ok := src.(bool); ok {
+		*destination = strconv.FormatBool(val)
+	} else if val, ok := src.([]byte); ok {
+		*destination = string(val)
+	} else if val, ok := src.(fmt.Stringer); ok {
+		*destination = val.String()
 	} else {
 		return util.ErrInvalidType(property, *destination, src)
 	}
@@ -0,0 +1,74 @@
+// standard/time.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"strings"
+	"time"
+
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+var (
+	// Layouts of strings converted to time.Time, tried in order.
+	// Layouts without offsets are parsed in time.Local.
+	TimeLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02",
+		"15:04:05.999999999",
+	}
+)
+
+func ConvertoTime(src, dst interface{}, property string) error {
+	if destination, ok := dst.(*time.Time); !ok {
+		panic(util.ErrInvalidType(property, destination, dst).Error())
+	} else if val, ok := src.(time.Time); ok {
+		*destination = val
+	} else if val, ok := src.(*time.Time); ok && val != nil {
+		*destination = *val
+	} else if val, ok := src.(string); ok {
+		if val, err := ParseTime(val); err != nil {
+			return util.ErrInvalidTime(property, src)
+		} else {
+			*destination = val
+		}
+	} else {
+		return util.ErrInvalidType(property, *destination, src)
+	}
+	return nil
+}
+
+// Parse the string with TimeLayouts.
+// Date and time may be separated by a space or lowercase t instead of T.
+func ParseTime(str string) (time.Time, error) {
+
+	if len(str) > 10 && (str[10] == ' ' || str[10] == 't') {
+		str = str[:10] + "T" + str[11:]
+	}
+	if strings.HasSuffix(str, "z") {
+		str = str[:len(str)-1] + "Z"
+	}
+
+	var err error
+	for _, layout := range TimeLayouts {
+		var val time.Time
+		if val, err = time.ParseInLocation(layout, str, time.Local); err == nil {
+			return val, nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -16,10 +16,13 @@
 package convertobject
 
 import (
+	"errors"
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 
+	"github.com/streamwest-1629/convertobject/standard"
 	"github.com/streamwest-1629/convertobject/util"
 )
 
@@ -28,9 +31,36 @@ const (
 	LabelRegexp        = `^(?P<key>[a-zA-Z0-9][a-zA-Z0-9_-]*)(!)?$`
 	LabelRequireRegexp = `[a-zA-Z0-9][a-zA-Z0-9_-]*(!)$`
 	LabelEmbed         = `<-`
+	LabelSeparator     = `,`
+
+	// Label option to select the encoding of strings converted to []byte or [N]byte: raw, base64 or hex.
+	OptionEncoding = `encoding`
 )
 
 var (
+	// Label options allowed for structure members, compiling labels with other options fails.
+	// Packages which read their own options from Member.Options register them.
+	LabelOptions = map[string]bool{
+		OptionEncoding:   true,
+		OptionNull:       true,
+		OptionMin:        true,
+		OptionMax:        true,
+		OptionLen:        true,
+		OptionMinLen:     true,
+		OptionMaxLen:     true,
+		OptionPattern:    true,
+		OptionOneOf:      true,
+		OptionNonEmpty:   true,
+		OptionRequiredIf: true,
+		OptionGroup:      true,
+		OptionExclusive:  true,
+		OptionAtLeastOne: true,
+		OptionMerge:      true,
+		OptionMergeKey:   true,
+		OptionDesc:       true,
+		OptionDefault:    true,
+	}
+
 	labelMatches        = regexp.MustCompile(LabelRegexp)
 	labelRequireMatches = regexp.MustCompile(LabelRequireRegexp)
 )
@@ -43,11 +73,7 @@ func CompileStruct(target interface{}) (compiled *Struct, err error) {
 
 	// initialize to check cache
 	__type := formatStructType(reflect.TypeOf(target))
-	if convert, err := selectConvert(__type, &PreCompiled); err != nil {
-		return nil, err
-	} else {
-		return convert.(*Struct), nil
-	}
+	return selectStruct(__type, &PreCompiled)
 
 }
 
@@ -58,11 +84,7 @@ func CompileStructIndepended(target interface{}) (compiled *Struct, err error) {
 
 	// compile
 	__type := formatStructType(reflect.TypeOf(target))
-	if convert, err := selectConvert(__type, &map[string]*Struct{}); err != nil {
-		return nil, err
-	} else {
-		return convert.(*Struct), nil
-	}
+	return selectStruct(__type, &map[string]*Struct{})
 
 }
 
@@ -116,12 +138,18 @@ func compileStruct(__type reflect.Type, compiled *Struct, cache *map[string]*Str
 		Members:         make([]Member, 0),
 		Type:            __type,
 		allowIntegerKey: true,
+		presenceAt:      -1,
 	}
 
 	for i, l := 0, __type.NumField(); i < l; i++ {
 
 		field := __type.Field(i)
-		label := field.Tag.Get(Label)
+		label, options := parseLabel(field.Tag.Get(Label))
+		for name := range options {
+			if !LabelOptions[name] {
+				return errors.New(field.Name + ": not supported label option: " + name)
+			}
+		}
 
 		if matches := labelMatches.FindStringSubmatchIndex(label); matches != nil {
 
@@ -134,8 +162,14 @@ func compileStruct(__type reflect.Type, compiled *Struct, cache *map[string]*Str
 				keynumber = id
 			}
 
-			if convert, err := selectConvert(field.Type, cache); err != nil {
+			if convert, err := selectMemberConvert(field.Type, options, cache); err != nil {
 				return err
+			} else if rules, err := compileRules(field.Type, options); err != nil {
+				return errors.New(field.Name + ": " + err.Error())
+			} else if null, nullSpecified, err := compileNullMode(options); err != nil {
+				return errors.New(field.Name + ": " + err.Error())
+			} else if err := compileMergePolicy(field.Type, options); err != nil {
+				return errors.New(field.Name + ": " + err.Error())
 			} else {
 
 				required := labelRequireMatches.MatchString(label)
@@ -147,26 +181,101 @@ func compileStruct(__type reflect.Type, compiled *Struct, cache *map[string]*Str
 						Keynumber: keynumber,
 						MemberAt:  i,
 						Required:  required,
+						Options:   options,
+						Rules:     rules,
+
+						null:          null,
+						nullSpecified: nullSpecified,
 					})
 			}
 		} else if label == LabelEmbed {
 
-			if convert, err := selectConvert(field.Type, cache); err != nil {
+			if convert, err := selectMemberConvert(field.Type, options, cache); err != nil {
 				return err
+			} else if rules, err := compileRules(field.Type, options); err != nil {
+				return errors.New(field.Name + ": " + err.Error())
 			} else {
 				compiled.Members = append(compiled.Members,
 					Member{
 						Convert:  convert,
 						Embed:    true,
 						MemberAt: i,
+						Options:  options,
+						Rules:    rules,
 					})
 			}
+		} else if label == LabelPresence {
+
+			if field.Type != presenceType {
+				return errors.New(field.Name + ": " + LabelPresence + " label is allowed only for " + util.TypeFullname(presenceType))
+			}
+			compiled.presenceAt = i
+		}
+	}
+	if err := compileConditions(compiled); err != nil {
+		return err
+	}
+	return compileGroups(compiled)
+}
+
+// Split label into the key part and options.
+// Options without '=' have empty value.
+func parseLabel(tag string) (label string, options map[string]string) {
+
+	parts := strings.Split(tag, LabelSeparator)
+	label, options = parts[0], make(map[string]string)
+
+	for _, option := range parts[1:] {
+		if option = strings.TrimSpace(option); len(option) == 0 {
+			continue
+		} else if at := strings.Index(option, "="); at < 0 {
+			options[option] = ""
+		} else {
+			options[strings.TrimSpace(option[:at])] = strings.TrimSpace(option[at+1:])
+		}
+	}
+	return
+}
+
+// Select converter of the structure member, some of which depend on label options.
+func selectMemberConvert(__type reflect.Type, options map[string]string, cache *map[string]*Struct) (Convert, error) {
+
+	if encoding, exist := options[OptionEncoding]; exist {
+		if __type.Kind() == reflect.Ptr {
+			elem := __type.Elem()
+			if gen, err := selectMemberConvert(elem, options, cache); err != nil {
+				return nil, err
+			} else {
+				return &Ptr{
+					gen:      elem,
+					Internal: gen,
+				}, nil
+			}
+		} else if !isBytes(__type) {
+			return nil, errors.New(OptionEncoding + " option is allowed only for []byte or [N]byte, but used for " + util.TypeFullname(__type))
+		} else if !standard.ValidEncoding(encoding) {
+			return nil, errors.New("not supported encoding: " + encoding)
+		} else {
+			return &Bytes{Type: __type, Encoding: encoding}, nil
 		}
 	}
-	return nil
+	return selectConvert(__type, cache)
 }
 
 func (c *Struct) Convert(src, dst interface{}, property string) error {
+	return c.convertOpts(src, dst, property, &Options{})
+}
+
+// Convert with the options, which are given to converters of members.
+func (c *Struct) ConvertWith(src, dst interface{}, property string, opts Options) error {
+	return c.convertOpts(src, dst, property, &opts)
+}
+
+func (c *Struct) convertOpts(src, dst interface{}, property string, opts *Options) error {
+
+	if positioned, ok := src.(*PositionedSource); ok {
+		return positioned.convert(c, dst, property, opts)
+	}
 
 	var (
 		val reflect.Value
@@ -183,132 +292,140 @@ func (c *Struct) Convert(src, dst interface{}, property string) error {
 		panic(util.ErrInvalidType(property, reflect.New(c.Type).Interface(), dst))
 	}
 
-	// initialize convert function
-	var MemberProperty func(member *Member) string
-	var AssignToMember = func(member *Member, src interface{}, property string) error {
-		dst := val.Field(member.MemberAt).Addr().Interface()
-		return member.Convert.Convert(src, dst, property)
+	lookup, ok := c.sourceLookup(src)
+	if !ok {
+		return util.ErrInvalidType(property, &map[string]interface{}{}, src)
+	} else if opts.Mode == ModeReset {
+		val.Set(reflect.Zero(val.Type()))
 	}
-	if len(property) > 0 {
-		MemberProperty = func(member *Member) string {
-			return property + "." + member.Keyname
+
+	errs := util.Errors{}
+	present := make([]bool, len(c.Members))
+	presence := c.presenceOf(val, opts)
+	memberProperty := func(member *Member) string {
+		return member.property(property)
+	}
+
+	for i := range c.Members {
+
+		member := &c.Members[i]
+		memProperty := member.property(property)
+
+		var err error
+		if member.Embed {
+			err = c.assignMember(val, member, src, memProperty, opts)
+		} else if buf, exist := lookup(member); exist {
+			present[i] = true
+			presence.mark(member, buf)
+			err = c.assignMember(val, member, buf, memProperty, opts)
+		} else if opts.Partial {
+			// absent members are left as they are
+		} else if member.Required {
+			// check property is required member
+			err = util.ErrCannotFound(memProperty)
+		} else {
+			err = member.checkAbsent(val.Field(member.MemberAt), memProperty)
 		}
-	} else {
-		MemberProperty = func(member *Member) string {
-			return member.Keyname
+
+		if err != nil {
+			if opts.Errors != CollectErrors {
+				return err
+			}
+			errs.Append(err)
 		}
 	}
 
-	// convert from map[interface{}]interface{}
-	if mapped, ok := src.(map[interface{}]interface{}); ok {
-		for _, member := range c.Members {
+	if len(errs) > 0 {
+		return errs
+	}
+	return c.validate(val, present, property, memberProperty, opts)
+}
 
-			memProperty := MemberProperty(&member)
+// Property of the member, embedded members have the same property as the parent.
+func (m *Member) property(parent string) string {
+	if m.Embed {
+		return parent
+	} else if len(parent) > 0 {
+		return parent + "." + m.Keyname
+	}
+	return m.Keyname
+}
 
-			if member.Embed {
-				if err := AssignToMember(&member, src, memProperty); err != nil {
-					return err
-				}
-				continue
-			} else if buf, exist := mapped[member.Keyname]; exist {
-				if err := AssignToMember(&member, buf, memProperty); err != nil {
-					return err
-				}
-				continue
+// Convert the source into the member of the structure value, and check its rules.
+func (c *Struct) assignMember(val reflect.Value, member *Member, src interface{}, property string, opts *Options) error {
+	field := val.Field(member.MemberAt)
+	if src == nil {
+		if err := assignNull(field, member.nullMode(opts), property); err != nil {
+			return err
+		}
+		return member.checkAbsent(field, property)
+	} else if err := convertWith(member.Convert, src, field.Addr().Interface(), property, opts); err != nil {
+		return err
+	}
+	return member.check(field, property, opts)
+}
+
+// Get presence member of the structure value, which is initialized unless merging.
+// Returns nil when the structure has no presence member.
+func (c *Struct) presenceOf(val reflect.Value, opts *Options) Presence {
+	if c.presenceAt < 0 {
+		return nil
+	}
+	field := val.Field(c.presenceAt)
+	presence, _ := field.Interface().(Presence)
+	if presence == nil || opts.Mode != ModeMerge {
+		presence = make(Presence)
+		field.Set(reflect.ValueOf(presence))
+	}
+	return presence
+}
+
+// Get function to look up member's value from the source map.
+// Returns false when the source type is not allowed.
+func (c *Struct) sourceLookup(src interface{}) (lookup func(member *Member) (interface{}, bool), ok bool) {
+
+	// convert from map[interface{}]interface{}
+	if mapped, ok := src.(map[interface{}]interface{}); ok {
+		return func(member *Member) (interface{}, bool) {
+			if buf, exist := mapped[member.Keyname]; exist {
+				return buf, true
 			} else if c.allowIntegerKey {
 				if buf, exist := mapped[int(member.Keynumber)]; exist {
-					if err := AssignToMember(&member, buf, memProperty); err != nil {
-						return err
-					}
-					continue
+					return buf, true
 				} else if buf, exist := mapped[int64(member.Keynumber)]; exist {
-					if err := AssignToMember(&member, buf, memProperty); err != nil {
-						return err
-					}
-					continue
+					return buf, true
 				}
 			}
-
-			// check property is required member
-			if member.Required {
-				return util.ErrCannotFound(memProperty)
-			}
-		}
-		return nil
+			return nil, false
+		}, true
 	}
 
 	// convert from map[string]interface{}
 	if mapped, ok := src.(map[string]interface{}); ok {
-		for _, member := range c.Members {
-
-			memProperty := MemberProperty(&member)
-			if member.Embed {
-				if err := AssignToMember(&member, src, memProperty); err != nil {
-					return err
-				}
-				continue
-			} else if buf, exist := mapped[member.Keyname]; exist {
-				if err := AssignToMember(&member, buf, memProperty); err != nil {
-					return err
-				}
-			} else if member.Required {
-				return util.ErrCannotFound(memProperty)
-			}
-		}
-
-		return nil
+		return func(member *Member) (interface{}, bool) {
+			buf, exist := mapped[member.Keyname]
+			return buf, exist
+		}, true
 	}
 
 	if c.allowIntegerKey {
 
 		// convert from map[int]interface{}
 		if mapped, ok := src.(map[int]interface{}); ok {
-
-			for _, member := range c.Members {
-
-				memProperty := MemberProperty(&member)
-
-				if member.Embed {
-					if err := AssignToMember(&member, src, memProperty); err != nil {
-						return err
-					}
-					continue
-				} else if buf, exist := mapped[int(member.Keynumber)]; exist {
-					if err := AssignToMember(&member, buf, memProperty); err != nil {
-						return err
-					}
-				} else if member.Required {
-					return util.ErrCannotFound(memProperty)
-				}
-			}
-
-			return nil
+			return func(member *Member) (interface{}, bool) {
+				buf, exist := mapped[int(member.Keynumber)]
+				return buf, exist
+			}, true
 		}
 
 		// convert from map[int64]interface{}
 		if mapped, ok := src.(map[int64]interface{}); ok {
-
-			for _, member := range c.Members {
-
-				memProperty := MemberProperty(&member)
-
-				if member.Embed {
-					if err := AssignToMember(&member, src, memProperty); err != nil {
-						return err
-					}
-					continue
-				} else if buf, exist := mapped[int64(member.Keynumber)]; exist {
-					if err := AssignToMember(&member, buf, memProperty); err != nil {
-						return err
-					}
-				} else if member.Required {
-					return util.ErrCannotFound(memProperty)
-				}
-			}
-
-			return nil
+			return func(member *Member) (interface{}, bool) {
+				buf, exist := mapped[int64(member.Keynumber)]
+				return buf, exist
+			}, true
 		}
 	}
 
-	return util.ErrInvalidType(property, &map[string]interface{}{}, src)
+	return nil, false
 }
@@ -15,7 +15,11 @@
 
 package convertobject
 
-import "reflect"
+import (
+	"reflect"
+
+	"github.com/streamwest-1629/convertobject/standard"
+)
 
 type (
 
@@ -27,17 +31,53 @@ type (
 	// The function to convert from unknown interface{} to value of the identifiered type.
 	ConvertFunc func(src, dst interface{}, property string) error
 
+	// The function to convert from unknown interface{} with the coercion policy, given by Options.Coercion.
+	CoercionFunc func(src, dst interface{}, property string, policy standard.CoercionPolicy) error
+
+	// The interface to convert from value of the identifiered type to builtin types, the reverse of Convert.
+	Encoder interface {
+		Encode(src interface{}, property string) (interface{}, error)
+	}
+
+	// The interface of named types which have constant names, declared with value or pointer receivers.
+	// Returned map's values must have the receiver's type.
+	EnumNamer interface {
+		EnumNames() map[string]interface{}
+	}
+
+	// The interface of EnumNamer types which select whether names are compared ignoring case.
+	// EnumNamer types not implementing it are compared case-sensitively.
+	EnumCaseInsensitive interface {
+		EnumCaseInsensitive() bool
+	}
+
 	// Defines to convert from unknown interface{} to the structure object.
 	// map[interface{}]interface{}, map[string]interface{} and map[int64]interface{}(optionally) are allowed types as src interface{}'s type.
 	//
 	// Source map object's key value are defined by member's label: `map-to:"keyname"`.
 	// Keyname is valid with regular expression [a-zA-Z0-9][a-zA-Z0-9_-]*.
 	// If a member is require value, append '!' to keyname. For example, `map-to:"dirname!"`
+	// Options follow keyname separated by ',', as `name=value` or `name`. For example, `map-to:"key!,encoding=hex"`
 	Struct struct {
 		// Defines rules assigning to member value.
-		Members         []Member
-		Type            reflect.Type
+		Members []Member
+		Type    reflect.Type
+		// Groups of members, which are checked with members set in the source map.
+		Groups          []Group
 		allowIntegerKey bool
+		conditions      []condition
+		presenceAt      int
+	}
+
+	// Defines the group of members declared with group option, such as `map-to:"password,group=auth,exclusive"`.
+	Group struct {
+		Name string
+		// The structure's member numbers, which index Struct.Members.
+		MembersAt []int
+		// Shows whether converter occurs error when more than one member is set.
+		Exclusive bool
+		// Shows whether converter occurs error when no member is set.
+		AtLeastOne bool
 	}
 
 	// Defines to convert from unknown interface{} to the structure's member object.
@@ -54,6 +94,96 @@ type (
 		Required bool
 		// Embed value, uses same map as given source value.
 		Embed bool
+		// Label options following the key name.
+		Options map[string]string
+		// Validation rules compiled from label options, checked after the member is converted.
+		Rules []Rule
+
+		null          NullMode
+		nullSpecified bool
+	}
+
+	// The interface to check converted value, fails with util.ViolationError.
+	Rule interface {
+		Check(val reflect.Value, property string) error
+	}
+
+	// The interface of structures which check themselves after their members are converted.
+	Validator interface {
+		Validate() error
+	}
+
+	// Named source, merged with other layers by ConvertLayers.
+	Layer struct {
+		Name   string
+		Source interface{}
+	}
+
+	// Maps property paths, such as `server.port`, to names of layers which supplied the values.
+	Origins map[string]string
+
+	// Defines how converters treat values already in the destination.
+	ConvertMode int
+
+	// Options of the conversion, given by ConvertWith and passed to converters of members and elements.
+	// The zero value converts with ModeOverwrite, NullSetsNil, FirstError and standard.Coercion.
+	Options struct {
+		// How converters treat values already in the destination.
+		Mode ConvertMode
+		// How converters treat nil values in the source, unless members have null option.
+		Null NullMode
+		// How converters report errors.
+		Errors ErrorMode
+		// Which source kinds are accepted by converters of builtin types, standard.CoercionDefault follows standard.Coercion.
+		Coercion standard.CoercionPolicy
+		// Converts the source which has only some of members, such as a layer of flags:
+		// required, required_if, group and nonempty checks of absent members are skipped.
+		Partial bool
+	}
+
+	// Defines to convert into types implementing sql.Scanner.
+	// Types shaped as sql.NullString, a value member followed by Valid bool, convert into the value member with Internal,
+	// and other types are given the source value by Scan.
+	// Other structure types, such as JSON columns, convert from sources which are not driver values, such as maps, with Struct.
+	Scanner struct {
+		Type     reflect.Type
+		Internal Convert
+		Struct   *Struct
+	}
+
+	// Defines to convert into maps, with the converter which accepts the map type.
+	Map struct {
+		Type     reflect.Type
+		Internal Convert
+	}
+
+	// Defines how converters treat nil values in the source.
+	NullMode int
+
+	// Records which members are found in the source map, by keyname.
+	// Assigned to the structure's member labeled `map-to:"<presence>"`.
+	Presence map[string]PresenceState
+
+	// Defines whether the member is found in the source map.
+	PresenceState int
+
+	// Defines how converters report errors.
+	ErrorMode int
+
+	// Defines to convert from strings decoded with the encoding, or []byte, to []byte or [N]byte.
+	Bytes struct {
+		Type     reflect.Type
+		Encoding string
+	}
+
+	// Defines to convert from constant names, or underlying values, to the named type.
+	// Values not registered in the name table are invalid.
+	Enum struct {
+		Type            reflect.Type
+		CaseInsensitive bool
+		names           []string
+		values          []reflect.Value
+		internal        Convert
 	}
 
 	// Defines to buffer instance and convert from interface{}, uses only structure instance.
@@ -69,6 +199,45 @@ type (
 	}
 )
 
+const (
+	// Values in the source replace destination values: slices and maps are newly allocated,
+	// while pointers are reused, so that T and *T members behave the same.
+	// Structure members absent in the source are left as they are.
+	ModeOverwrite ConvertMode = iota
+	// Values in the source are merged into destination values: in addition to ModeOverwrite,
+	// slice elements are converted into existing elements by index, entries are added to existing maps,
+	// and presence members keep previous records.
+	// Structure members absent in the source are left as they are.
+	ModeMerge
+	// Destination values are reset to zero before conversion, so structure members absent in the source become zero.
+	ModeReset
+)
+
+const (
+	// nil sets pointers, slices and maps to nil, and is an error for other types.
+	NullSetsNil NullMode = iota
+	// nil sets the zero value of any type.
+	NullZeroes
+	// nil is an error for any type.
+	NullIsError
+)
+
+const (
+	// The member is not found in the source map.
+	PresenceAbsent PresenceState = iota
+	// The member is found in the source map with nil.
+	PresenceNull
+	// The member is found in the source map with non-nil value.
+	PresenceSet
+)
+
+const (
+	// Converters stop at the first error and return it.
+	FirstError ErrorMode = iota
+	// Converters continue after errors, and return all of them as util.Errors.
+	CollectErrors
+)
+
 var (
 	// Pre-compiled converters from maps to struct.
 	PreCompiled = make(map[string]*Struct)
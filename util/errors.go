@@ -15,9 +15,30 @@
 
 package util
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
 
 type (
+	// The error which occurs at the property.
+	PropertyError interface {
+		error
+		Property() string
+	}
+
+	// Errors collected by the multi-error mode.
+	Errors []error
+
+	// The error of the value which violates the validation rule.
+	ViolationError struct {
+		PropName string
+		Rule     string
+		Value    string
+	}
+
 	errInvalidType struct {
 		propName string
 		wantType string
@@ -26,8 +47,66 @@ type (
 	errCannotFound struct {
 		propName string
 	}
+	errInvalidEnum struct {
+		propName string
+		value    string
+		allowed  []string
+	}
+	errOutOfRange struct {
+		propName string
+		wantType string
+		value    string
+	}
+	errNotIntegral struct {
+		propName string
+		value    string
+	}
+	errInvalidNumber struct {
+		propName string
+		value    string
+	}
+	errInvalidEncoding struct {
+		propName string
+		encoding string
+		offset   int
+	}
+	errRequiredIf struct {
+		propName  string
+		otherName string
+	}
+	errGroup struct {
+		propName  string
+		groupName string
+		members   []string
+		set       []string
+		exclusive bool
+	}
+	errNull struct {
+		propName string
+		wantType string
+	}
+	errAtProperty struct {
+		propName string
+		err      error
+	}
+	errInvalidLength struct {
+		propName string
+		want     int
+		has      int
+	}
+	errAtPosition struct {
+		position string
+		err      error
+	}
+	errInvalidTime struct {
+		propName string
+		value    string
+	}
 )
 
+func (e *errInvalidType) Property() string {
+	return e.propName
+}
 func (e *errInvalidType) Error() string {
 	return e.propName + " is invalid type (want: " + e.wantType + ", has: " + e.hasType + ")"
 }
@@ -39,6 +118,9 @@ func ErrInvalidType(propName string, want interface{}, has interface{}) error {
 	}
 }
 
+func (e *errCannotFound) Property() string {
+	return e.propName
+}
 func (e *errCannotFound) Error() string {
 	return e.propName + " is required property, but cannot found it"
 }
@@ -47,3 +129,251 @@ func ErrCannotFound(propName string) error {
 		propName: propName,
 	}
 }
+
+func (e *errInvalidEnum) Property() string {
+	return e.propName
+}
+func (e *errInvalidEnum) Error() string {
+	return e.propName + " is invalid value " + e.value + " (allowed: " + strings.Join(e.allowed, ", ") + ")"
+}
+func ErrInvalidEnum(propName string, has interface{}, allowed []string) error {
+	return &errInvalidEnum{
+		propName: propName,
+		value:    formatValue(has),
+		allowed:  allowed,
+	}
+}
+
+func (e *errOutOfRange) Property() string {
+	return e.propName
+}
+func (e *errOutOfRange) Error() string {
+	return e.propName + " is out of range of " + e.wantType + " (has: " + e.value + ")"
+}
+func ErrOutOfRange(propName string, want interface{}, has interface{}) error {
+	return &errOutOfRange{
+		propName: propName,
+		wantType: TypeFullname(reflect.TypeOf(want)),
+		value:    formatValue(has),
+	}
+}
+
+func (e *errNotIntegral) Property() string {
+	return e.propName
+}
+func (e *errNotIntegral) Error() string {
+	return e.propName + " is not integral number (has: " + e.value + ")"
+}
+func ErrNotIntegral(propName string, has interface{}) error {
+	return &errNotIntegral{
+		propName: propName,
+		value:    formatValue(has),
+	}
+}
+
+func (e *errInvalidNumber) Property() string {
+	return e.propName
+}
+func (e *errInvalidNumber) Error() string {
+	return e.propName + " is invalid number (has: " + e.value + ")"
+}
+func ErrInvalidNumber(propName string, has interface{}) error {
+	return &errInvalidNumber{
+		propName: propName,
+		value:    formatValue(has),
+	}
+}
+
+func (e *errInvalidEncoding) Property() string {
+	return e.propName
+}
+func (e *errInvalidEncoding) Error() string {
+	return e.propName + " is invalid " + e.encoding + " string (at offset " + strconv.Itoa(e.offset) + ")"
+}
+func ErrInvalidEncoding(propName string, encoding string, offset int) error {
+	return &errInvalidEncoding{
+		propName: propName,
+		encoding: encoding,
+		offset:   offset,
+	}
+}
+
+func (e *errInvalidTime) Property() string {
+	return e.propName
+}
+func (e *errInvalidTime) Error() string {
+	return e.propName + " is invalid time (has: " + e.value + ")"
+}
+func ErrInvalidTime(propName string, has interface{}) error {
+	return &errInvalidTime{
+		propName: propName,
+		value:    formatValue(has),
+	}
+}
+
+func (e *errInvalidLength) Property() string {
+	return e.propName
+}
+func (e *errInvalidLength) Error() string {
+	return e.propName + " is invalid length (want: " + strconv.Itoa(e.want) + ", has: " + strconv.Itoa(e.has) + ")"
+}
+func ErrInvalidLength(propName string, want int, has int) error {
+	return &errInvalidLength{
+		propName: propName,
+		want:     want,
+		has:      has,
+	}
+}
+
+func (e *errRequiredIf) Property() string {
+	return e.propName
+}
+func (e *errRequiredIf) Error() string {
+	return e.propName + " is required property when " + e.otherName + " is set, but cannot found it"
+}
+func ErrRequiredIf(propName string, otherName string) error {
+	return &errRequiredIf{
+		propName:  propName,
+		otherName: otherName,
+	}
+}
+
+func (e *errGroup) Property() string {
+	return e.propName
+}
+func (e *errGroup) Error() string {
+	prefix := ""
+	if len(e.propName) > 0 {
+		prefix = e.propName + ": "
+	}
+	if e.exclusive {
+		return prefix + strings.Join(e.members, ", ") + " are mutually exclusive (group " + e.groupName + "), but " +
+			strings.Join(e.set, ", ") + " are set"
+	}
+	return prefix + "at least one of " + strings.Join(e.members, ", ") + " is required (group " + e.groupName + "), but cannot found them"
+}
+func ErrGroupExclusive(propName string, groupName string, members []string, set []string) error {
+	return &errGroup{
+		propName:  propName,
+		groupName: groupName,
+		members:   members,
+		set:       set,
+		exclusive: true,
+	}
+}
+func ErrGroupAtLeastOne(propName string, groupName string, members []string) error {
+	return &errGroup{
+		propName:  propName,
+		groupName: groupName,
+		members:   members,
+	}
+}
+
+func (e *errNull) Property() string {
+	return e.propName
+}
+func (e *errNull) Error() string {
+	return e.propName + " is null, but " + e.wantType + " cannot be null"
+}
+func ErrNull(propName string, want interface{}) error {
+	return &errNull{
+		propName: propName,
+		wantType: TypeFullname(reflect.TypeOf(want)),
+	}
+}
+
+func (e *errAtProperty) Property() string {
+	return e.propName
+}
+func (e *errAtProperty) Error() string {
+	return e.propName + ": " + e.err.Error()
+}
+func (e *errAtProperty) Unwrap() error {
+	return e.err
+}
+
+func (e *errAtPosition) Property() string {
+	if err, ok := e.err.(PropertyError); ok {
+		return err.Property()
+	}
+	return ""
+}
+func (e *errAtPosition) Error() string {
+	return e.position + ": " + e.err.Error()
+}
+func (e *errAtPosition) Unwrap() error {
+	return e.err
+}
+
+// Prefix the error with the position in the source, such as `config.toml:14:3`.
+func ErrAtPosition(position string, err error) error {
+	if len(position) == 0 || err == nil {
+		return err
+	}
+	return &errAtPosition{
+		position: position,
+		err:      err,
+	}
+}
+
+// Prefix the error with the property, unless the property is empty.
+func ErrAtProperty(propName string, err error) error {
+	if len(propName) == 0 || err == nil {
+		return err
+	}
+	return &errAtProperty{
+		propName: propName,
+		err:      err,
+	}
+}
+
+func (e *ViolationError) Property() string {
+	return e.PropName
+}
+func (e *ViolationError) Error() string {
+	return e.PropName + " violates " + e.Rule + " (has: " + e.Value + ")"
+}
+func ErrViolation(propName string, rule string, has interface{}) error {
+	return &ViolationError{
+		PropName: propName,
+		Rule:     rule,
+		Value:    formatValue(has),
+	}
+}
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns collected errors.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// Append the error, flattening nested Errors.
+func (e *Errors) Append(err error) {
+	if errs, ok := err.(Errors); ok {
+		*e = append(*e, errs...)
+	} else if err != nil {
+		*e = append(*e, err)
+	}
+}
+
+// Err returns nil when no errors are collected, otherwise returns the Errors.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func formatValue(val interface{}) string {
+	if reflect.ValueOf(val).Kind() == reflect.String {
+		return fmt.Sprintf("%q", val)
+	}
+	return fmt.Sprintf("%v", val)
+}
@@ -41,6 +41,10 @@ func Typename(__type reflect.Type) (pkg string, name string) {
 		Other = "%s"
 	)
 
+	if __type == nil {
+		return "", "nil"
+	}
+
 	switch __type.Kind() {
 	case reflect.Ptr:
 		pkg, name := Typename(__type.Elem())
@@ -0,0 +1,469 @@
+// validate.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+const (
+	// Label option of the minimum number, for example `map-to:"port,min=1"`.
+	OptionMin = `min`
+	// Label option of the maximum number, for example `map-to:"port,max=65535"`.
+	OptionMax = `max`
+	// Label option of the exact length of strings, slices, arrays and maps.
+	OptionLen = `len`
+	// Label option of the minimum length of strings, slices, arrays and maps.
+	OptionMinLen = `minlen`
+	// Label option of the maximum length of strings, slices, arrays and maps.
+	OptionMaxLen = `maxlen`
+	// Label option of the regular expression which strings must match.
+	// The expression cannot contain ',', which separates options.
+	OptionPattern = `pattern`
+	// Label option of allowed values separated by '|', for example `map-to:"level,oneof=debug|info"`.
+	OptionOneOf = `oneof`
+	// Label option to forbid zero value and empty strings, slices and maps.
+	// It is checked against the member's final value, also when the member is absent or null in the source.
+	// Other rules check only values converted from the source, and skip absent and null members.
+	OptionNonEmpty = `nonempty`
+	// Label option to require the member when the other member is set, for example `map-to:"cert,required_if=enabled"`.
+	// When the other member's value follows, as `required_if=mode=tls`, it is required only when the other member has the value.
+	OptionRequiredIf = `required_if`
+	// Label option of the group name which the member belongs to, checked with exclusive and atleastone options.
+	OptionGroup = `group`
+	// Label option to allow at most one member of the group to be set, for example `map-to:"password,group=auth,exclusive"`.
+	OptionExclusive = `exclusive`
+	// Label option to require at least one member of the group to be set.
+	OptionAtLeastOne = `atleastone`
+)
+
+var (
+	// Validator functions called after members of the structure type are converted.
+	Validators = make(map[reflect.Type]func(target interface{}) error)
+
+	validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+)
+
+type (
+	numberRule struct {
+		name   string
+		bound  string
+		max    bool
+		signed int64
+		unsign uint64
+		float  float64
+	}
+	lengthRule struct {
+		name  string
+		bound int
+		cmp   int
+	}
+	patternRule struct {
+		pattern *regexp.Regexp
+	}
+	oneOfRule struct {
+		values []string
+	}
+	nonEmptyRule struct{}
+
+	// Requires the member when the other member is set.
+	condition struct {
+		memberAt int
+		otherAt  int
+		value    string
+		hasValue bool
+	}
+)
+
+// Register validator function of the structure type, called after its members are converted.
+// The function is given the pointer to the structure.
+func RegisterValidator(target interface{}, validator func(target interface{}) error) {
+	Validators[formatStructType(reflect.TypeOf(target))] = validator
+}
+
+// Compile rules from label options, for values of the type.
+func compileRules(__type reflect.Type, options map[string]string) (rules []Rule, err error) {
+
+	for __type.Kind() == reflect.Ptr {
+		__type = __type.Elem()
+	}
+	kind := __type.Kind()
+
+	for _, name := range []string{OptionMin, OptionMax} {
+		if bound, exist := options[name]; exist {
+			if rule, err := newNumberRule(name, bound, kind); err != nil {
+				return nil, err
+			} else {
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	for _, name := range []string{OptionLen, OptionMinLen, OptionMaxLen} {
+		if bound, exist := options[name]; exist {
+			switch kind {
+			case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+			default:
+				return nil, errors.New(name + " option is not allowed for " + util.TypeFullname(__type))
+			}
+			if length, err := strconv.Atoi(bound); err != nil || length < 0 {
+				return nil, errors.New(name + " option requires non-negative integer: " + bound)
+			} else {
+				cmp := map[string]int{OptionLen: 0, OptionMinLen: -1, OptionMaxLen: 1}[name]
+				rules = append(rules, &lengthRule{name: name, bound: length, cmp: cmp})
+			}
+		}
+	}
+
+	if pattern, exist := options[OptionPattern]; exist {
+		if kind != reflect.String {
+			return nil, errors.New(OptionPattern + " option is not allowed for " + util.TypeFullname(__type))
+		} else if re, err := regexp.Compile(pattern); err != nil {
+			return nil, err
+		} else {
+			rules = append(rules, &patternRule{pattern: re})
+		}
+	}
+
+	if values, exist := options[OptionOneOf]; exist {
+		if _, ok := formatScalar(reflect.Zero(__type)); !ok {
+			return nil, errors.New(OptionOneOf + " option is not allowed for " + util.TypeFullname(__type))
+		}
+		rules = append(rules, &oneOfRule{values: strings.Split(values, "|")})
+	}
+
+	if _, exist := options[OptionNonEmpty]; exist {
+		rules = append(rules, &nonEmptyRule{})
+	}
+
+	return rules, nil
+}
+
+// Check the member which is absent or null in the source, whose value is left or assigned by null handling.
+// Only nonempty rules are checked against the value, other rules check only converted values.
+func (m *Member) checkAbsent(val reflect.Value, property string) error {
+	for _, rule := range m.Rules {
+		if _, ok := rule.(*nonEmptyRule); ok {
+			return rule.Check(val, property)
+		}
+	}
+	return nil
+}
+
+// Check the converted value satisfies member's rules.
+func (m *Member) check(val reflect.Value, property string, opts *Options) error {
+
+	errs := util.Errors{}
+	for _, rule := range m.Rules {
+		if err := rule.Check(val, property); err != nil {
+			if opts.Errors != CollectErrors {
+				return err
+			}
+			errs.Append(err)
+		}
+	}
+	return errs.Err()
+}
+
+// Compile conditions from required_if options, referring to other members by keyname.
+func compileConditions(compiled *Struct) error {
+
+	for i := range compiled.Members {
+
+		member := &compiled.Members[i]
+		option, exist := member.Options[OptionRequiredIf]
+		if !exist {
+			continue
+		}
+
+		cond := condition{memberAt: i, otherAt: -1}
+		keyname := option
+		if at := strings.Index(option, "="); at >= 0 {
+			keyname, cond.value, cond.hasValue = option[:at], option[at+1:], true
+		}
+		for j := range compiled.Members {
+			if other := &compiled.Members[j]; !other.Embed && other.Keyname == keyname {
+				cond.otherAt = j
+			}
+		}
+		if cond.otherAt < 0 {
+			return errors.New(member.Keyname + ": " + OptionRequiredIf + " option refers unknown member: " + keyname)
+		}
+		compiled.conditions = append(compiled.conditions, cond)
+	}
+	return nil
+}
+
+// Compile groups from group options.
+// A group is exclusive or requires at least one member when any of its members has the option.
+func compileGroups(compiled *Struct) error {
+
+	for i := range compiled.Members {
+
+		member := &compiled.Members[i]
+		_, exclusive := member.Options[OptionExclusive]
+		_, atLeastOne := member.Options[OptionAtLeastOne]
+
+		name, exist := member.Options[OptionGroup]
+		if !exist {
+			if exclusive || atLeastOne {
+				return errors.New(member.Keyname + ": " + OptionExclusive + " and " + OptionAtLeastOne + " options require " + OptionGroup + " option")
+			}
+			continue
+		} else if len(name) == 0 {
+			return errors.New(member.Keyname + ": " + OptionGroup + " option requires group name")
+		} else if member.Embed {
+			return errors.New(OptionGroup + " option is not allowed for embedded member")
+		}
+
+		var group *Group
+		for j := range compiled.Groups {
+			if compiled.Groups[j].Name == name {
+				group = &compiled.Groups[j]
+			}
+		}
+		if group == nil {
+			compiled.Groups = append(compiled.Groups, Group{Name: name})
+			group = &compiled.Groups[len(compiled.Groups)-1]
+		}
+		group.MembersAt = append(group.MembersAt, i)
+		group.Exclusive = group.Exclusive || exclusive
+		group.AtLeastOne = group.AtLeastOne || atLeastOne
+	}
+	return nil
+}
+
+// Check members which are absent satisfy conditions and groups, and call validators of the structure.
+// present reports whether each member is found in the source map. Conditions and groups are not checked for partial sources.
+func (c *Struct) validate(val reflect.Value, present []bool, property string, memberProperty func(member *Member) string, opts *Options) error {
+
+	conditions, groups := c.conditions, c.Groups
+	if opts.Partial {
+		conditions, groups = nil, nil
+	}
+
+	errs := util.Errors{}
+	for _, cond := range conditions {
+
+		if present[cond.memberAt] || !present[cond.otherAt] {
+			continue
+		}
+
+		other := val.Field(c.Members[cond.otherAt].MemberAt)
+		if cond.hasValue {
+			if str, _ := formatScalar(indirect(other)); str != cond.value {
+				continue
+			}
+		} else if other = indirect(other); !other.IsValid() || other.IsZero() {
+			continue
+		}
+
+		err := util.ErrRequiredIf(memberProperty(&c.Members[cond.memberAt]), memberProperty(&c.Members[cond.otherAt]))
+		if opts.Errors != CollectErrors {
+			return err
+		}
+		errs.Append(err)
+	}
+
+	for _, group := range groups {
+
+		names, set := make([]string, len(group.MembersAt)), []string{}
+		for i, at := range group.MembersAt {
+			names[i] = memberProperty(&c.Members[at])
+			if present[at] {
+				set = append(set, names[i])
+			}
+		}
+
+		var err error
+		if group.Exclusive && len(set) > 1 {
+			err = util.ErrGroupExclusive(property, group.Name, names, set)
+		} else if group.AtLeastOne && len(set) == 0 {
+			err = util.ErrGroupAtLeastOne(property, group.Name, names)
+		} else {
+			continue
+		}
+		if opts.Errors != CollectErrors {
+			return err
+		}
+		errs.Append(err)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	// structure's validators
+	target := val.Addr().Interface()
+	if validator, exist := Validators[c.Type]; exist {
+		if err := validator(target); err != nil {
+			return util.ErrAtProperty(property, err)
+		}
+	}
+	if reflect.PtrTo(c.Type).Implements(validatorType) {
+		if err := target.(Validator).Validate(); err != nil {
+			return util.ErrAtProperty(property, err)
+		}
+	}
+	return nil
+}
+
+func newNumberRule(name, bound string, kind reflect.Kind) (rule *numberRule, err error) {
+
+	rule = &numberRule{name: name, bound: bound, max: name == OptionMax}
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		rule.signed, err = strconv.ParseInt(bound, 0, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		rule.unsign, err = strconv.ParseUint(bound, 0, 64)
+	case reflect.Float32, reflect.Float64:
+		rule.float, err = strconv.ParseFloat(bound, 64)
+	default:
+		return nil, errors.New(name + " option is allowed only for numbers, but used for " + kind.String())
+	}
+	if err != nil {
+		return nil, errors.New(name + " option has invalid bound: " + bound)
+	}
+	return rule, nil
+}
+
+func (r *numberRule) Check(val reflect.Value, property string) error {
+
+	if val = indirect(val); !val.IsValid() {
+		return nil
+	}
+
+	cmp := 0
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		cmp = compare(val.Int() < r.signed, val.Int() > r.signed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		cmp = compare(val.Uint() < r.unsign, val.Uint() > r.unsign)
+	case reflect.Float32, reflect.Float64:
+		cmp = compare(val.Float() < r.float, val.Float() > r.float)
+	}
+
+	if (r.max && cmp > 0) || (!r.max && cmp < 0) {
+		return util.ErrViolation(property, r.name+"="+r.bound, val.Interface())
+	}
+	return nil
+}
+
+func (r *lengthRule) Check(val reflect.Value, property string) error {
+
+	if val = indirect(val); !val.IsValid() {
+		return nil
+	}
+
+	length := val.Len()
+	if val.Kind() == reflect.String {
+		length = utf8.RuneCountInString(val.String())
+	}
+
+	if cmp := compare(length < r.bound, length > r.bound); cmp != 0 && (r.cmp == 0 || cmp == r.cmp) {
+		return util.ErrViolation(property, r.name+"="+strconv.Itoa(r.bound), length)
+	}
+	return nil
+}
+
+func (r *patternRule) Check(val reflect.Value, property string) error {
+
+	if val = indirect(val); val.IsValid() && !r.pattern.MatchString(val.String()) {
+		return util.ErrViolation(property, OptionPattern+"="+r.pattern.String(), val.String())
+	}
+	return nil
+}
+
+func (r *oneOfRule) Check(val reflect.Value, property string) error {
+
+	if val = indirect(val); !val.IsValid() {
+		return nil
+	}
+
+	str, _ := formatScalar(val)
+	for _, allowed := range r.values {
+		if str == allowed {
+			return nil
+		}
+	}
+	return util.ErrViolation(property, OptionOneOf+"="+strings.Join(r.values, "|"), val.Interface())
+}
+
+func (r *nonEmptyRule) Check(val reflect.Value, property string) error {
+
+	// nil pointers at any level are empty
+	for val.Kind() == reflect.Ptr && !val.IsNil() {
+		val = val.Elem()
+	}
+
+	empty := false
+	switch val.Kind() {
+	case reflect.Ptr:
+		empty = true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		empty = val.Len() == 0
+	default:
+		empty = val.IsZero()
+	}
+
+	if empty {
+		return util.ErrViolation(property, OptionNonEmpty, val.Interface())
+	}
+	return nil
+}
+
+// Dereference pointers, returns invalid value when it is nil.
+func indirect(val reflect.Value) reflect.Value {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return reflect.Value{}
+		}
+		val = val.Elem()
+	}
+	return val
+}
+
+func compare(less, greater bool) int {
+	if less {
+		return -1
+	} else if greater {
+		return 1
+	}
+	return 0
+}
+
+// Format value of string, integer and float kinds, the same as written in labels.
+func formatScalar(val reflect.Value) (string, bool) {
+	switch val.Kind() {
+	case reflect.String:
+		return val.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(val.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'g', -1, val.Type().Bits()), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,254 @@
+// validate_test.go
+// Copyright (C) 2021 Kasai Koji
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convertobject_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/streamwest-1629/convertobject"
+	"github.com/streamwest-1629/convertobject/util"
+)
+
+type validated struct {
+	Port  int               `map-to:"port,min=1,max=65535"`
+	Name  string            `map-to:"name,minlen=2,maxlen=8,pattern=^[a-z]+$"`
+	Level string            `map-to:"level,oneof=debug|info|warn"`
+	Tags  []string          `map-to:"tags,nonempty"`
+	Extra map[string]string `map-to:"extra,len=1"`
+	Ratio *float64          `map-to:"ratio,max=1.0"`
+}
+
+func TestValidationRules(t *testing.T) {
+
+	valid := map[string]interface{}{
+		"port":  8080,
+		"name":  "server",
+		"level": "info",
+		"tags":  []interface{}{"a"},
+		"extra": map[string]interface{}{"k": "v"},
+		"ratio": 0.5,
+	}
+	if err := convertobject.DirectConvert(valid, &validated{}); err != nil {
+		t.Errorf("valid source: %s", err.Error())
+	}
+
+	for key, val := range map[string]interface{}{
+		"port":  0,
+		"name":  "Server",
+		"level": "trace",
+		"tags":  []interface{}{},
+		"extra": map[string]interface{}{},
+		"ratio": 1.5,
+	} {
+		src := make(map[string]interface{})
+		for k, v := range valid {
+			src[k] = v
+		}
+		src[key] = val
+
+		violation := &util.ViolationError{}
+		if err := convertobject.DirectConvert(src, &validated{}); !errors.As(err, &violation) {
+			t.Errorf("%s: want violation, has %v", key, err)
+		} else if violation.PropName != key {
+			t.Errorf("%s: violation has property %s", key, violation.PropName)
+		}
+	}
+}
+
+func TestNonEmptyAbsent(t *testing.T) {
+
+	type target struct {
+		Tags []string `map-to:"tags,nonempty"`
+		Port int      `map-to:"port,min=1"`
+	}
+
+	for name, src := range map[string]map[string]interface{}{
+		"absent": {},
+		"null":   {"tags": nil},
+	} {
+		violation := &util.ViolationError{}
+		if err := convertobject.DirectConvert(src, &target{}); !errors.As(err, &violation) {
+			t.Errorf("%s: want violation, has %v", name, err)
+		} else if violation.PropName != "tags" {
+			t.Errorf("%s: violation has property %s", name, violation.PropName)
+		}
+		if err := convertobject.DecodeJSON(strings.NewReader(map[string]string{"absent": `{}`, "null": `{"tags":null}`}[name]), &target{}); !errors.As(err, &violation) {
+			t.Errorf("%s: DecodeJSON want violation, has %v", name, err)
+		}
+	}
+
+	// the value left in the destination is checked, and min is not checked for absent members
+	if err := convertobject.DirectConvert(map[string]interface{}{}, &target{Tags: []string{"a"}}); err != nil {
+		t.Errorf("left value: %s", err.Error())
+	}
+}
+
+func TestValidationFloat32AndPointers(t *testing.T) {
+
+	type validatedNested struct {
+		Ratio float32  `map-to:"ratio,oneof=0.1|0.25"`
+		Name  **string `map-to:"name,nonempty"`
+	}
+
+	name := "a"
+	named := &name
+	if err := convertobject.DirectConvert(map[string]interface{}{"ratio": 0.1}, &validatedNested{Name: &named}); err != nil {
+		t.Errorf("float32 oneof: %s", err.Error())
+	}
+
+	// the inner nil pointer of **string is empty
+	violation := &util.ViolationError{}
+	if err := convertobject.DirectConvert(map[string]interface{}{"ratio": 0.25}, &validatedNested{Name: new(*string)}); !errors.As(err, &violation) {
+		t.Errorf("nil inner pointer: want violation, has %v", err)
+	} else if violation.PropName != "name" {
+		t.Errorf("nil inner pointer: violation has property %s", violation.PropName)
+	}
+}
+
+func TestUnknownOption(t *testing.T) {
+
+	type target struct {
+		Port int `map-to:"port,mni=1"`
+	}
+	if _, err := convertobject.CompileStructIndepended(target{}); err == nil {
+		t.Error("want error of unknown option")
+	} else if err.Error() != "Port: not supported label option: mni" {
+		t.Errorf("has %q", err.Error())
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+
+	src := map[string]interface{}{
+		"port":  70000,
+		"name":  "X",
+		"level": "info",
+		"tags":  []interface{}{"a"},
+	}
+
+	err := convertobject.ConvertWith(src, &validated{}, convertobject.Options{Errors: convertobject.CollectErrors})
+	if errs, ok := err.(util.Errors); !ok {
+		t.Fatalf("want util.Errors, has %v", err)
+	} else if len(errs) != 3 {
+		t.Errorf("want 3 errors (port, name minlen, name pattern), has %d:\n%s", len(errs), errs.Error())
+	}
+}
+
+type window struct {
+	Start int `map-to:"start"`
+	End   int `map-to:"end"`
+}
+
+func (w *window) Validate() error {
+	if w.Start >= w.End {
+		return errors.New("start must be less than end")
+	}
+	return nil
+}
+
+type tlsConfig struct {
+	Enabled bool   `map-to:"enabled"`
+	Mode    string `map-to:"mode"`
+	Cert    string `map-to:"cert,required_if=enabled"`
+	CA      string `map-to:"ca,required_if=mode=verify"`
+}
+
+func TestValidateHook(t *testing.T) {
+
+	type schedule struct {
+		Windows []window   `map-to:"windows"`
+		TLS     *tlsConfig `map-to:"tls"`
+	}
+
+	src := map[string]interface{}{
+		"windows": []interface{}{
+			map[string]interface{}{"start": 1, "end": 2},
+			map[string]interface{}{"start": 3, "end": 3},
+		},
+	}
+	if err := convertobject.DirectConvert(src, &schedule{}); err == nil {
+		t.Error("invalid window: want error")
+	} else if want := "windows[1]: start must be less than end"; err.Error() != want {
+		t.Errorf("want %q, has %q", want, err.Error())
+	}
+
+	for _, c := range []struct {
+		tls   map[string]interface{}
+		valid bool
+	}{
+		{map[string]interface{}{"enabled": false}, true},
+		{map[string]interface{}{"enabled": true}, false},
+		{map[string]interface{}{"enabled": true, "cert": "cert.pem"}, true},
+		{map[string]interface{}{"mode": "verify", "cert": "cert.pem"}, false},
+		{map[string]interface{}{"mode": "none"}, true},
+	} {
+		err := convertobject.DirectConvert(map[string]interface{}{"tls": c.tls}, &schedule{})
+		if c.valid && err != nil {
+			t.Errorf("%v: %s", c.tls, err.Error())
+		} else if !c.valid && err == nil {
+			t.Errorf("%v: want error", c.tls)
+		}
+	}
+}
+
+func TestRegisterValidator(t *testing.T) {
+
+	type limits struct {
+		Soft int `map-to:"soft"`
+		Hard int `map-to:"hard"`
+	}
+	convertobject.RegisterValidator(limits{}, func(target interface{}) error {
+		if l := target.(*limits); l.Soft > l.Hard {
+			return errors.New("soft exceeds hard")
+		}
+		return nil
+	})
+
+	if err := convertobject.DirectConvert(map[string]interface{}{"soft": 2, "hard": 1}, &limits{}); err == nil {
+		t.Error("want error from registered validator")
+	}
+}
+
+func TestGroups(t *testing.T) {
+
+	type credentials struct {
+		Password     string `map-to:"password,group=auth,exclusive"`
+		PasswordFile string `map-to:"password_file,group=auth"`
+		URL          string `map-to:"url,group=endpoint,atleastone"`
+		Host         string `map-to:"host,group=endpoint"`
+	}
+
+	for _, c := range []struct {
+		src  map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{"password": "p", "url": "u"}, ""},
+		{map[string]interface{}{"password_file": "f", "host": "h", "url": "u"}, ""},
+		{map[string]interface{}{"password": "p", "password_file": "f", "url": "u"},
+			"password, password_file are mutually exclusive (group auth), but password, password_file are set"},
+		{map[string]interface{}{"password": "p"},
+			"at least one of url, host is required (group endpoint), but cannot found them"},
+	} {
+		err := convertobject.DirectConvert(c.src, &credentials{})
+		if len(c.want) == 0 && err != nil {
+			t.Errorf("%v: %s", c.src, err.Error())
+		} else if len(c.want) > 0 && (err == nil || err.Error() != c.want) {
+			t.Errorf("%v: want %q, has %v", c.src, c.want, err)
+		}
+	}
+}